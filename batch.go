@@ -0,0 +1,237 @@
+package orderedmap
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// Pair is a key-value pair passed to SetMany.
+type Pair struct {
+	Key   any
+	Value any
+}
+
+// Result is the outcome of looking up one key with GetMany.
+type Result struct {
+	Key   any  // The key that was looked up
+	Value any  // The value, or nil if the key was not found
+	Found bool // Whether the key exists in the map
+}
+
+// BatchOptions configures the batch operations.
+type BatchOptions struct {
+	// AbortOnError stops the batch at the first failing key. Entries before
+	// it remain applied. By default failing keys are skipped and the rest of
+	// the batch is processed.
+	AbortOnError bool
+}
+
+// KeyError describes why a single entry of a batch failed.
+type KeyError struct {
+	Index int   // Position of the entry in the batch
+	Key   any   // The key of the entry
+	Err   error // The underlying error
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("entry %d (key %v): %v", e.Index, e.Key, e.Err)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError is returned by the batch operations when one or more entries
+// failed. Entries that are not listed were applied.
+type BatchError struct {
+	Errors []*KeyError
+}
+
+func (e *BatchError) Error() string {
+	if len(e.Errors) == 1 {
+		return "batch: " + e.Errors[0].Error()
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "batch: %d entries failed", len(e.Errors))
+	for _, err := range e.Errors {
+		sb.WriteString("; ")
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// batch collects per-entry errors for one batch operation.
+type batch struct {
+	opts   *BatchOptions
+	errors []*KeyError
+}
+
+func newBatch(opts *BatchOptions) *batch {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+	return &batch{opts: opts}
+}
+
+// fail records an error and reports whether the batch should go on.
+func (b *batch) fail(index int, key any, err error) bool {
+	b.errors = append(b.errors, &KeyError{Index: index, Key: key, Err: err})
+	return !b.opts.AbortOnError
+}
+
+func (b *batch) err() error {
+	if len(b.errors) == 0 {
+		return nil
+	}
+	return &BatchError{Errors: b.errors}
+}
+
+// SetMany adds or updates all pairs in order while holding the lock once.
+// Pairs with a nil key are skipped and reported in a *BatchError; the other
+// pairs are still applied. This method is thread-safe.
+//
+// Example:
+//
+//	err := om.SetMany(
+//	    Pair{Key: "a", Value: 1},
+//	    Pair{Key: "b", Value: 2},
+//	)
+func (om *OrderedMap) SetMany(pairs ...Pair) error {
+	return om.SetManyWithOptions(nil, pairs...)
+}
+
+// SetManyWithOptions is like SetMany but allows the error handling to be
+// configured. If opts is nil, default options are used.
+// This method is thread-safe.
+//
+// Example:
+//
+//	err := om.SetManyWithOptions(&BatchOptions{AbortOnError: true}, pairs...)
+func (om *OrderedMap) SetManyWithOptions(opts *BatchOptions, pairs ...Pair) error {
+	b := newBatch(opts)
+
+	om.lock()
+	defer om.unlock()
+
+	om.grow(len(pairs))
+	for i, p := range pairs {
+		if err := om.set(p.Key, p.Value); err != nil && !b.fail(i, p.Key, err) {
+			break
+		}
+	}
+	return b.err()
+}
+
+// SetFromSeq adds or updates all pairs produced by seq while holding the
+// lock once. Pairs with a nil key are skipped and reported in a *BatchError.
+// seq must not access the map. This method is thread-safe.
+//
+// Example:
+//
+//	err := om.SetFromSeq(maps.All(source))
+func (om *OrderedMap) SetFromSeq(seq iter.Seq2[any, any]) error {
+	return om.SetFromSeqWithOptions(nil, seq)
+}
+
+// SetFromSeqWithOptions is like SetFromSeq but allows the error handling to
+// be configured. If opts is nil, default options are used.
+// This method is thread-safe.
+func (om *OrderedMap) SetFromSeqWithOptions(opts *BatchOptions, seq iter.Seq2[any, any]) error {
+	b := newBatch(opts)
+
+	om.lock()
+	defer om.unlock()
+
+	i := 0
+	for key, value := range seq {
+		if err := om.set(key, value); err != nil && !b.fail(i, key, err) {
+			break
+		}
+		i++
+	}
+	return b.err()
+}
+
+// DeleteMany removes all given keys while holding the lock once. Missing
+// keys are ignored; nil keys are skipped and reported in a *BatchError.
+// This method is thread-safe.
+//
+// Example:
+//
+//	err := om.DeleteMany("a", "b", "c")
+func (om *OrderedMap) DeleteMany(keys ...any) error {
+	return om.DeleteManyWithOptions(nil, keys...)
+}
+
+// DeleteManyWithOptions is like DeleteMany but allows the error handling to
+// be configured. If opts is nil, default options are used.
+// This method is thread-safe.
+func (om *OrderedMap) DeleteManyWithOptions(opts *BatchOptions, keys ...any) error {
+	b := newBatch(opts)
+
+	om.lock()
+	defer om.unlock()
+
+	for i, key := range keys {
+		if key == nil {
+			if !b.fail(i, key, fmt.Errorf("key cannot be nil")) {
+				break
+			}
+			continue
+		}
+		if node, exists := om.lookup(key); exists {
+			om.remove(node)
+		}
+	}
+	return b.err()
+}
+
+// GetMany looks up all given keys under a single read lock and returns one
+// Result per key, in the same order. Nil keys are reported as not found.
+// This method is thread-safe.
+//
+// Example:
+//
+//	for _, r := range om.GetMany("a", "b") {
+//	    if r.Found {
+//	        fmt.Println(r.Key, r.Value)
+//	    }
+//	}
+func (om *OrderedMap) GetMany(keys ...any) []Result {
+	om.rlock()
+	defer om.runlock()
+
+	results := make([]Result, len(keys))
+	for i, key := range keys {
+		results[i].Key = key
+		if node, exists := om.lookup(key); exists {
+			results[i].Value = node.Value
+			results[i].Found = true
+		}
+	}
+	return results
+}
+
+// grow makes room for n more keys in the node index so that a large batch
+// doesn't rehash repeatedly. It is a no-op for small batches.
+func (om *OrderedMap) grow(n int) {
+	if n <= om.length || n < 64 {
+		// Incremental growth is cheap enough relative to the current size.
+		return
+	}
+	om.prepareWrite()
+	nodeMap := make(map[any]*Node, om.length+n)
+	for key, node := range om.nodeMap {
+		nodeMap[key] = node
+	}
+	om.nodeMap = nodeMap
+}
@@ -0,0 +1,143 @@
+package orderedmap
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOrderedMap_SetMany(t *testing.T) {
+	t.Run("Insert And Update", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set("b", 0)
+		err := om.SetMany(Pair{"a", 1}, Pair{"b", 2}, Pair{"c", 3})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if om.String() != "{b: 2, a: 1, c: 3}" {
+			t.Errorf("Unexpected state %s", om.String())
+		}
+	})
+
+	t.Run("Large Batch", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set(-1, -1)
+		snap := om.Snapshot()
+		pairs := make([]Pair, 1000)
+		for i := range pairs {
+			pairs[i] = Pair{i, i * 2}
+		}
+		if err := om.SetMany(pairs...); err != nil {
+			t.Fatal(err)
+		}
+		if om.Len() != 1001 {
+			t.Errorf("Expected 1001 elements, got %d", om.Len())
+		}
+		if k, _, _ := om.First(); k != -1 {
+			t.Errorf("Expected order to be kept, first is %v", k)
+		}
+		if v, _ := om.Get(999); v != 1998 {
+			t.Errorf("Expected 1998, got %v", v)
+		}
+		if snap.Len() != 1 || snap.Has(0) {
+			t.Error("Snapshot changed by SetMany")
+		}
+	})
+
+	t.Run("Nil Keys Are Reported", func(t *testing.T) {
+		om := NewOrderedMap()
+		err := om.SetMany(Pair{"a", 1}, Pair{nil, 2}, Pair{"c", 3}, Pair{nil, 4})
+		var batchErr *BatchError
+		if !errors.As(err, &batchErr) {
+			t.Fatalf("Expected *BatchError, got %v", err)
+		}
+		if len(batchErr.Errors) != 2 || batchErr.Errors[0].Index != 1 || batchErr.Errors[1].Index != 3 {
+			t.Errorf("Unexpected errors %v", batchErr.Errors)
+		}
+		if om.String() != "{a: 1, c: 3}" {
+			t.Errorf("Expected valid pairs to be applied, got %s", om.String())
+		}
+	})
+
+	t.Run("Abort On Error", func(t *testing.T) {
+		om := NewOrderedMap()
+		err := om.SetManyWithOptions(&BatchOptions{AbortOnError: true},
+			Pair{"a", 1}, Pair{nil, 2}, Pair{"c", 3})
+		var keyErr *KeyError
+		if !errors.As(err, &keyErr) || keyErr.Index != 1 {
+			t.Errorf("Expected KeyError at index 1, got %v", err)
+		}
+		if om.String() != "{a: 1}" {
+			t.Errorf("Expected batch to stop at the nil key, got %s", om.String())
+		}
+	})
+}
+
+func TestOrderedMap_SetFromSeq(t *testing.T) {
+	om := NewOrderedMap()
+	seq := func(yield func(any, any) bool) {
+		for _, k := range []any{"x", nil, "y", "z"} {
+			if !yield(k, k) {
+				return
+			}
+		}
+	}
+
+	err := om.SetFromSeq(seq)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Errors) != 1 {
+		t.Errorf("Expected one error, got %v", err)
+	}
+	if om.String() != "{x: x, y: y, z: z}" {
+		t.Errorf("Unexpected state %s", om.String())
+	}
+
+	aborted := NewOrderedMap()
+	if err := aborted.SetFromSeqWithOptions(&BatchOptions{AbortOnError: true}, seq); err == nil {
+		t.Error("Expected error")
+	}
+	if aborted.Len() != 1 {
+		t.Errorf("Expected sequence to stop after the first error, got %d elements", aborted.Len())
+	}
+}
+
+func TestOrderedMap_DeleteMany(t *testing.T) {
+	om := NewOrderedMap()
+	for i := 0; i < 5; i++ {
+		om.Set(i, i)
+	}
+
+	if err := om.DeleteMany(1, 3, 42); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(om.Keys(), []any{0, 2, 4}) {
+		t.Errorf("Unexpected keys %v", om.Keys())
+	}
+
+	err := om.DeleteMany(nil, 0)
+	if err == nil || om.Has(0) {
+		t.Errorf("Expected nil key error and 0 deleted, got %v", err)
+	}
+
+	err = om.DeleteManyWithOptions(&BatchOptions{AbortOnError: true}, nil, 2)
+	if err == nil || !om.Has(2) {
+		t.Errorf("Expected batch to abort before deleting 2, got %v", err)
+	}
+}
+
+func TestOrderedMap_GetMany(t *testing.T) {
+	om := NewOrderedMap()
+	om.Set("a", 1)
+	om.Set("b", 2)
+
+	results := om.GetMany("b", "missing", nil, "a")
+	expected := []Result{
+		{Key: "b", Value: 2, Found: true},
+		{Key: "missing"},
+		{Key: nil},
+		{Key: "a", Value: 1, Found: true},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+}
@@ -0,0 +1,145 @@
+package orderedmap
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Cloner is implemented by values that know how to deep-copy themselves.
+// Clone calls CloneValue on every value implementing it instead of sharing
+// the value.
+type Cloner interface {
+	CloneValue() any
+}
+
+// CloneOptions configures CloneWithOptions.
+type CloneOptions struct {
+	// Funcs maps a value type to a function returning a deep copy of a value
+	// of that type. It takes precedence over the built-in rules and Cloner.
+	Funcs map[reflect.Type]func(v any) any
+}
+
+// Clone returns a deep copy of the map. Unlike Copy, values are copied
+// recursively:
+//
+//   - nested *OrderedMap values are cloned,
+//   - []any and map[string]any values are copied element by element,
+//   - values implementing Cloner are replaced by the result of CloneValue,
+//   - all other values are copied as they are.
+//
+// References that form a cycle, or that point to the same nested map, slice
+// or map more than once, are cloned once and the clone is shared the same
+// way, so cyclic structures don't cause infinite recursion. The clone keeps
+// the options the map was created with. This method is thread-safe.
+//
+// Example:
+//
+//	clone := config.Clone()
+//	clone.Set("servers", append(servers, "new"))
+//	// config is unaffected
+func (om *OrderedMap) Clone() *OrderedMap {
+	return om.CloneWithOptions(nil)
+}
+
+// CloneWithOptions is like Clone but applies user-supplied clone functions.
+// If opts is nil, default options are used. This method is thread-safe.
+//
+// Example:
+//
+//	clone := om.CloneWithOptions(&CloneOptions{
+//	    Funcs: map[reflect.Type]func(any) any{
+//	        reflect.TypeOf(&User{}): func(v any) any {
+//	            u := *v.(*User)
+//	            return &u
+//	        },
+//	    },
+//	})
+func (om *OrderedMap) CloneWithOptions(opts *CloneOptions) *OrderedMap {
+	if opts == nil {
+		opts = &CloneOptions{}
+	}
+	c := &cloner{opts: opts, seen: make(map[cloneRef]any)}
+	return c.cloneMap(om)
+}
+
+// cloneRef identifies a reference value that has already been cloned.
+type cloneRef struct {
+	typ reflect.Type
+	ptr unsafe.Pointer
+	n   int // Length, for slices
+}
+
+type cloner struct {
+	opts *CloneOptions
+	seen map[cloneRef]any
+}
+
+func (c *cloner) cloneMap(om *OrderedMap) *OrderedMap {
+	ref := cloneRef{typ: reflect.TypeOf(om), ptr: unsafe.Pointer(om)}
+	if done, ok := c.seen[ref]; ok {
+		return done.(*OrderedMap)
+	}
+
+	om.rlock()
+	defer om.runlock()
+
+	newMap := om.derive(om.length)
+	newMap.layout = om.layout
+	// Register before descending, so a reference back to om (which would
+	// otherwise lock it again) resolves to the clone under construction.
+	c.seen[ref] = newMap
+	for current := om.head; current != nil; current = current.next {
+		// Keys are already normalized; don't pass them through the normalizer again.
+		_ = newMap.setNormalized(current.Key, c.clone(current.Value))
+	}
+	return newMap
+}
+
+func (c *cloner) clone(v any) any {
+	if v == nil {
+		return nil
+	}
+	if fn, ok := c.opts.Funcs[reflect.TypeOf(v)]; ok {
+		return fn(v)
+	}
+
+	switch x := v.(type) {
+	case *OrderedMap:
+		if x == nil {
+			return x
+		}
+		return c.cloneMap(x)
+	case []any:
+		if x == nil {
+			return x
+		}
+		ref := cloneRef{typ: reflect.TypeOf(x), ptr: unsafe.Pointer(unsafe.SliceData(x)), n: len(x)}
+		if done, ok := c.seen[ref]; ok {
+			return done
+		}
+		out := make([]any, len(x))
+		c.seen[ref] = out
+		for i, elem := range x {
+			out[i] = c.clone(elem)
+		}
+		return out
+	case map[string]any:
+		if x == nil {
+			return x
+		}
+		ref := cloneRef{typ: reflect.TypeOf(x), ptr: reflect.ValueOf(x).UnsafePointer()}
+		if done, ok := c.seen[ref]; ok {
+			return done
+		}
+		out := make(map[string]any, len(x))
+		c.seen[ref] = out
+		for k, elem := range x {
+			out[k] = c.clone(elem)
+		}
+		return out
+	case Cloner:
+		return x.CloneValue()
+	default:
+		return v
+	}
+}
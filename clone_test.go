@@ -0,0 +1,168 @@
+package orderedmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+type cloneCounter struct{ n int }
+
+func (c *cloneCounter) CloneValue() any {
+	return &cloneCounter{n: c.n}
+}
+
+func TestOrderedMap_Clone(t *testing.T) {
+	nested := NewOrderedMap()
+	nested.Set("inner", []any{1, 2})
+
+	om := NewOrderedMap()
+	om.Set("map", nested)
+	om.Set("list", []any{"a", map[string]any{"k": "v"}})
+	om.Set("dict", map[string]any{"items": []any{1}})
+	om.Set("counter", &cloneCounter{n: 1})
+	om.Set("plain", 42)
+	om.Set("nil", nil)
+
+	clone := om.Clone()
+	if !clone.Equal(om) {
+		t.Fatalf("Expected clone to equal the original: %s", clone.String())
+	}
+
+	// Mutate everything reachable from the clone.
+	cm, _ := clone.Get("map")
+	cm.(*OrderedMap).Set("added", true)
+	inner, _ := cm.(*OrderedMap).Get("inner")
+	inner.([]any)[0] = "changed"
+	list, _ := clone.Get("list")
+	list.([]any)[1].(map[string]any)["k"] = "changed"
+	dict, _ := clone.Get("dict")
+	dict.(map[string]any)["items"].([]any)[0] = "changed"
+	counter, _ := clone.Get("counter")
+	counter.(*cloneCounter).n = 99
+
+	if nested.Has("added") {
+		t.Error("Nested map shared with the clone")
+	}
+	if v, _ := nested.Get("inner"); v.([]any)[0] != 1 {
+		t.Error("Nested slice shared with the clone")
+	}
+	if v, _ := om.Get("list"); v.([]any)[1].(map[string]any)["k"] != "v" {
+		t.Error("Map inside slice shared with the clone")
+	}
+	if v, _ := om.Get("dict"); v.(map[string]any)["items"].([]any)[0] != 1 {
+		t.Error("Slice inside map shared with the clone")
+	}
+	if v, _ := om.Get("counter"); v.(*cloneCounter).n != 1 {
+		t.Error("Cloner value shared with the clone")
+	}
+}
+
+func TestOrderedMap_CloneCopyIsShallow(t *testing.T) {
+	om := NewOrderedMap()
+	om.Set("list", []any{1})
+	copied := om.Copy()
+	v, _ := copied.Get("list")
+	v.([]any)[0] = "changed"
+	if orig, _ := om.Get("list"); orig.([]any)[0] != "changed" {
+		t.Error("Expected Copy to share values, as documented")
+	}
+}
+
+func TestOrderedMap_CloneNormalizedKeys(t *testing.T) {
+	om := New(WithKeyNormalizer(func(key any) any { return "x" + key.(string) }))
+	om.Set("a", 1)
+	clone := om.Clone()
+	if clone.String() != "{xa: 1}" {
+		t.Errorf("Expected {xa: 1}, got %s", clone.String())
+	}
+	if v, _ := clone.Get("a"); v != 1 {
+		t.Errorf("Expected the clone to keep the normalizer, got %v", v)
+	}
+}
+
+func TestOrderedMap_CloneCycles(t *testing.T) {
+	t.Run("Self Reference", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set("name", "root")
+		om.Set("self", om)
+
+		clone := om.Clone()
+		self, _ := clone.Get("self")
+		if self.(*OrderedMap) != clone {
+			t.Error("Expected the cycle to point at the clone")
+		}
+	})
+
+	t.Run("Mutual Reference", func(t *testing.T) {
+		a, b := NewOrderedMap(), NewOrderedMap()
+		a.Set("b", b)
+		b.Set("a", a)
+
+		clone := a.Clone()
+		cb, _ := clone.Get("b")
+		ca, _ := cb.(*OrderedMap).Get("a")
+		if ca.(*OrderedMap) != clone || cb.(*OrderedMap) == b {
+			t.Error("Expected the mutual reference to be cloned once")
+		}
+	})
+
+	t.Run("Slice Cycle And Sharing", func(t *testing.T) {
+		s := make([]any, 2)
+		s[0] = s
+		s[1] = "x"
+		shared := map[string]any{"k": 1}
+
+		om := NewOrderedMap()
+		om.Set("cycle", s)
+		om.Set("one", shared)
+		om.Set("two", shared)
+
+		clone := om.Clone()
+		cs, _ := clone.Get("cycle")
+		inner := cs.([]any)[0].([]any)
+		if &inner[0] != &cs.([]any)[0] {
+			t.Error("Expected the slice cycle to point at the cloned slice")
+		}
+		one, _ := clone.Get("one")
+		two, _ := clone.Get("two")
+		one.(map[string]any)["k"] = 2
+		if two.(map[string]any)["k"] != 2 || shared["k"] != 1 {
+			t.Error("Expected shared references to stay shared within the clone only")
+		}
+	})
+}
+
+func TestOrderedMap_CloneWithOptions(t *testing.T) {
+	type user struct{ Name string }
+
+	om := New(WithMaxLen(10))
+	original := &user{Name: "alice"}
+	om.Set("u", original)
+	om.Set("tags", []string{"a"})
+
+	clone := om.CloneWithOptions(&CloneOptions{
+		Funcs: map[reflect.Type]func(any) any{
+			reflect.TypeOf(&user{}): func(v any) any {
+				u := *v.(*user)
+				return &u
+			},
+			reflect.TypeOf([]string{}): func(v any) any {
+				return append([]string(nil), v.([]string)...)
+			},
+		},
+	})
+
+	u, _ := clone.Get("u")
+	u.(*user).Name = "bob"
+	tags, _ := clone.Get("tags")
+	tags.([]string)[0] = "changed"
+	if original.Name != "alice" {
+		t.Error("Expected the clone function to be used for *user")
+	}
+	if v, _ := om.Get("tags"); v.([]string)[0] != "a" {
+		t.Error("Expected the clone function to be used for []string")
+	}
+	if clone.maxLen != 10 {
+		t.Error("Expected the clone to keep the map's options")
+	}
+}
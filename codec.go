@@ -0,0 +1,366 @@
+package orderedmap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"unsafe"
+)
+
+// Codec converts keys and values to and from bytes for the binary
+// persistence formats (snapshots and write-ahead logs).
+// Implementations must be safe for concurrent use.
+type Codec interface {
+	// Encode returns the binary representation of v.
+	Encode(v any) ([]byte, error)
+	// Decode reconstructs a value from the output of Encode.
+	Decode(data []byte) (any, error)
+}
+
+// BinaryCodec is the default Codec. Each value is written as a one byte type
+// tag followed by a compact payload, so decoded values keep their exact Go type
+// (an int64 stays an int64, a float32 stays a float32) instead of collapsing to
+// float64 the way JSON does.
+//
+// Supported types are nil, bool, all sized and unsized integer types, float32,
+// float64, string, []byte, []any, map[string]any and *OrderedMap. Containers are
+// encoded recursively; map[string]any entries are written in sorted key order so
+// the output is deterministic. Encode returns an error if containers form a
+// cycle.
+type BinaryCodec struct{}
+
+// Type tags used by BinaryCodec. The numeric values are part of the on-disk
+// format and must never be reused or renumbered.
+const (
+	tagNil byte = iota
+	tagFalse
+	tagTrue
+	tagInt
+	tagInt8
+	tagInt16
+	tagInt32
+	tagInt64
+	tagUint
+	tagUint8
+	tagUint16
+	tagUint32
+	tagUint64
+	tagFloat32
+	tagFloat64
+	tagString
+	tagBytes
+	tagSlice
+	tagMap
+	tagOrderedMap
+)
+
+// errShortBuffer is returned when an encoded value ends prematurely.
+var errShortBuffer = errors.New("unexpected end of encoded value")
+
+// Encode implements the Codec interface.
+func (BinaryCodec) Encode(v any) ([]byte, error) {
+	var e binaryEncoder
+	return e.appendValue(nil, v)
+}
+
+// Decode implements the Codec interface.
+func (BinaryCodec) Decode(data []byte) (any, error) {
+	v, rest, err := readValue(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after encoded value", len(rest))
+	}
+	return v, nil
+}
+
+// binaryEncoder encodes one value for BinaryCodec.
+type binaryEncoder struct {
+	encoding map[cloneRef]bool // Containers on the current path, to detect cycles
+}
+
+// enter marks a container as being encoded, or returns an error if it
+// already is. A nested map must be checked before it is locked, since it may
+// already be read-locked further up the path.
+func (e *binaryEncoder) enter(ref cloneRef) error {
+	if e.encoding[ref] {
+		return fmt.Errorf("binary codec: cycle detected in %v", ref.typ)
+	}
+	if e.encoding == nil {
+		e.encoding = make(map[cloneRef]bool)
+	}
+	e.encoding[ref] = true
+	return nil
+}
+
+func (e *binaryEncoder) appendValue(buf []byte, v any) ([]byte, error) {
+	switch x := v.(type) {
+	case nil:
+		return append(buf, tagNil), nil
+	case bool:
+		if x {
+			return append(buf, tagTrue), nil
+		}
+		return append(buf, tagFalse), nil
+	case int:
+		return binary.AppendVarint(append(buf, tagInt), int64(x)), nil
+	case int8:
+		return append(buf, tagInt8, byte(x)), nil
+	case int16:
+		return binary.BigEndian.AppendUint16(append(buf, tagInt16), uint16(x)), nil
+	case int32:
+		return binary.BigEndian.AppendUint32(append(buf, tagInt32), uint32(x)), nil
+	case int64:
+		return binary.BigEndian.AppendUint64(append(buf, tagInt64), uint64(x)), nil
+	case uint:
+		return binary.AppendUvarint(append(buf, tagUint), uint64(x)), nil
+	case uint8:
+		return append(buf, tagUint8, x), nil
+	case uint16:
+		return binary.BigEndian.AppendUint16(append(buf, tagUint16), x), nil
+	case uint32:
+		return binary.BigEndian.AppendUint32(append(buf, tagUint32), x), nil
+	case uint64:
+		return binary.BigEndian.AppendUint64(append(buf, tagUint64), x), nil
+	case float32:
+		return binary.BigEndian.AppendUint32(append(buf, tagFloat32), math.Float32bits(x)), nil
+	case float64:
+		return binary.BigEndian.AppendUint64(append(buf, tagFloat64), math.Float64bits(x)), nil
+	case string:
+		buf = binary.AppendUvarint(append(buf, tagString), uint64(len(x)))
+		return append(buf, x...), nil
+	case []byte:
+		buf = binary.AppendUvarint(append(buf, tagBytes), uint64(len(x)))
+		return append(buf, x...), nil
+	case []any:
+		if len(x) > 0 {
+			ref := cloneRef{typ: reflect.TypeOf(x), ptr: unsafe.Pointer(unsafe.SliceData(x)), n: len(x)}
+			if err := e.enter(ref); err != nil {
+				return nil, err
+			}
+			defer delete(e.encoding, ref)
+		}
+		buf = binary.AppendUvarint(append(buf, tagSlice), uint64(len(x)))
+		var err error
+		for _, elem := range x {
+			if buf, err = e.appendValue(buf, elem); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case map[string]any:
+		if len(x) > 0 {
+			ref := cloneRef{typ: reflect.TypeOf(x), ptr: reflect.ValueOf(x).UnsafePointer()}
+			if err := e.enter(ref); err != nil {
+				return nil, err
+			}
+			defer delete(e.encoding, ref)
+		}
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf = binary.AppendUvarint(append(buf, tagMap), uint64(len(keys)))
+		var err error
+		for _, k := range keys {
+			buf = binary.AppendUvarint(buf, uint64(len(k)))
+			buf = append(buf, k...)
+			if buf, err = e.appendValue(buf, x[k]); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case *OrderedMap:
+		ref := cloneRef{typ: reflect.TypeOf(x), ptr: unsafe.Pointer(x)}
+		if err := e.enter(ref); err != nil {
+			return nil, err
+		}
+		defer delete(e.encoding, ref)
+		x.rlock()
+		defer x.runlock()
+		buf = binary.AppendUvarint(append(buf, tagOrderedMap), uint64(x.length))
+		var err error
+		for current := x.head; current != nil; current = current.next {
+			if buf, err = e.appendValue(buf, current.Key); err != nil {
+				return nil, err
+			}
+			if buf, err = e.appendValue(buf, current.Value); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	default:
+		return nil, fmt.Errorf("binary codec: unsupported type %T", v)
+	}
+}
+
+func readValue(data []byte) (any, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, errShortBuffer
+	}
+	tag, data := data[0], data[1:]
+	switch tag {
+	case tagNil:
+		return nil, data, nil
+	case tagFalse:
+		return false, data, nil
+	case tagTrue:
+		return true, data, nil
+	case tagInt:
+		v, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, nil, errShortBuffer
+		}
+		return int(v), data[n:], nil
+	case tagInt8, tagUint8:
+		if len(data) < 1 {
+			return nil, nil, errShortBuffer
+		}
+		if tag == tagInt8 {
+			return int8(data[0]), data[1:], nil
+		}
+		return data[0], data[1:], nil
+	case tagInt16, tagUint16:
+		if len(data) < 2 {
+			return nil, nil, errShortBuffer
+		}
+		v := binary.BigEndian.Uint16(data)
+		if tag == tagInt16 {
+			return int16(v), data[2:], nil
+		}
+		return v, data[2:], nil
+	case tagInt32, tagUint32, tagFloat32:
+		if len(data) < 4 {
+			return nil, nil, errShortBuffer
+		}
+		v := binary.BigEndian.Uint32(data)
+		switch tag {
+		case tagInt32:
+			return int32(v), data[4:], nil
+		case tagUint32:
+			return v, data[4:], nil
+		}
+		return math.Float32frombits(v), data[4:], nil
+	case tagInt64, tagUint64, tagFloat64:
+		if len(data) < 8 {
+			return nil, nil, errShortBuffer
+		}
+		v := binary.BigEndian.Uint64(data)
+		switch tag {
+		case tagInt64:
+			return int64(v), data[8:], nil
+		case tagUint64:
+			return v, data[8:], nil
+		}
+		return math.Float64frombits(v), data[8:], nil
+	case tagUint:
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, nil, errShortBuffer
+		}
+		return uint(v), data[n:], nil
+	case tagString, tagBytes:
+		b, rest, err := readBytes(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if tag == tagString {
+			return string(b), rest, nil
+		}
+		return append([]byte(nil), b...), rest, nil
+	case tagSlice:
+		n, rest, err := readCount(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		s := make([]any, 0, n)
+		for i := 0; i < n; i++ {
+			var elem any
+			if elem, rest, err = readValue(rest); err != nil {
+				return nil, nil, err
+			}
+			s = append(s, elem)
+		}
+		return s, rest, nil
+	case tagMap:
+		n, rest, err := readCount(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		m := make(map[string]any, n)
+		for i := 0; i < n; i++ {
+			var k []byte
+			if k, rest, err = readBytes(rest); err != nil {
+				return nil, nil, err
+			}
+			var elem any
+			if elem, rest, err = readValue(rest); err != nil {
+				return nil, nil, err
+			}
+			m[string(k)] = elem
+		}
+		return m, rest, nil
+	case tagOrderedMap:
+		n, rest, err := readCount(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		om := NewOrderedMap()
+		for i := 0; i < n; i++ {
+			var k, elem any
+			if k, rest, err = readValue(rest); err != nil {
+				return nil, nil, err
+			}
+			if elem, rest, err = readValue(rest); err != nil {
+				return nil, nil, err
+			}
+			if !isHashable(k) {
+				return nil, nil, fmt.Errorf("binary codec: unhashable key type %T", k)
+			}
+			if err := om.set(k, elem); err != nil {
+				return nil, nil, err
+			}
+		}
+		return om, rest, nil
+	default:
+		return nil, nil, fmt.Errorf("binary codec: unknown type tag %d", tag)
+	}
+}
+
+// readBytes reads a uvarint length prefix followed by that many bytes.
+// The returned slice aliases data.
+func readBytes(data []byte) ([]byte, []byte, error) {
+	n, rest, err := readCount(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rest) < n {
+		return nil, nil, errShortBuffer
+	}
+	return rest[:n], rest[n:], nil
+}
+
+// readCount reads a uvarint element count. Every element occupies at least
+// one byte, so counts larger than the remaining input are rejected before
+// anything is allocated.
+func readCount(data []byte) (int, []byte, error) {
+	v, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, nil, errShortBuffer
+	}
+	data = data[n:]
+	if v > uint64(len(data)) {
+		return 0, nil, errShortBuffer
+	}
+	return int(v), data, nil
+}
+
+// isHashable reports whether key can be used as a Go map key without panicking.
+func isHashable(key any) bool {
+	return key == nil || reflect.TypeOf(key).Comparable()
+}
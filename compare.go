@@ -0,0 +1,295 @@
+package orderedmap
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Equal reports whether om and other have the same keys in the same order
+// with equal values. Values are compared with reflect.DeepEqual, except that
+// nested *OrderedMap values are compared with Equal. Like reflect.DeepEqual,
+// a pair of nested maps met again while it is being compared counts as
+// equal, so maps that contain themselves can be compared. This method is
+// thread-safe, and om and other may be the same map.
+//
+// Example:
+//
+//	if !current.Equal(saved) {
+//	    save(current)
+//	}
+func (om *OrderedMap) Equal(other *OrderedMap) bool {
+	var c comparer
+	return c.equal(om, other)
+}
+
+// EqualFunc is like Equal but compares values with eq. This method is
+// thread-safe. eq must not modify either map.
+//
+// Example:
+//
+//	// Ignore case differences in string values
+//	same := a.EqualFunc(b, func(x, y any) bool {
+//	    return strings.EqualFold(x.(string), y.(string))
+//	})
+func (om *OrderedMap) EqualFunc(other *OrderedMap, eq func(a, b any) bool) bool {
+	defer rlockPair(om, other)()
+	return equalLocked(om, other, eq)
+}
+
+// equalLocked is the body of EqualFunc. The caller must hold the read locks
+// of both maps.
+func equalLocked(om, other *OrderedMap, eq func(a, b any) bool) bool {
+	if om.length != other.length {
+		return false
+	}
+	for x, y := om.head, other.head; x != nil; x, y = x.next, y.next {
+		if x.Key != y.Key || !eq(x.Value, y.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualUnordered reports whether om and other have the same keys with equal
+// values, regardless of order. Values are compared as in Equal. This method
+// is thread-safe.
+//
+// Example:
+//
+//	a.Set("x", 1)
+//	a.Set("y", 2)
+//	b.Set("y", 2)
+//	b.Set("x", 1)
+//	a.EqualUnordered(b) // true
+func (om *OrderedMap) EqualUnordered(other *OrderedMap) bool {
+	var c comparer
+	defer c.enter(om, other)()
+
+	if om.length != other.length {
+		return false
+	}
+	for current := om.head; current != nil; current = current.next {
+		node, exists := other.nodeMap[current.Key]
+		if !exists || !c.values(current.Value, node.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// valuesEqual is the value comparison used by Equal and Compare.
+func valuesEqual(a, b any) bool {
+	var c comparer
+	return c.values(a, b)
+}
+
+// comparer compares values that may contain nested maps. It remembers the
+// pairs of maps being compared, to stop at cycles, and the maps it has
+// read-locked, so a map is never locked twice.
+type comparer struct {
+	comparing map[[2]*OrderedMap]bool
+	locked    map[*OrderedMap]int
+}
+
+func (c *comparer) values(a, b any) bool {
+	if x, ok := a.(*OrderedMap); ok {
+		if y, ok := b.(*OrderedMap); ok && x != nil && y != nil {
+			return c.equal(x, y)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func (c *comparer) equal(x, y *OrderedMap) bool {
+	// Check for a cycle before locking, since both maps are already
+	// read-locked further up if the pair is being compared.
+	if c.comparing[[2]*OrderedMap{x, y}] {
+		return true
+	}
+	defer c.enter(x, y)()
+	return equalLocked(x, y, c.values)
+}
+
+// enter marks x and y as being compared and read-locks those that are not
+// locked yet. The returned function undoes both.
+func (c *comparer) enter(x, y *OrderedMap) (leave func()) {
+	if c.comparing == nil {
+		c.comparing = make(map[[2]*OrderedMap]bool)
+		c.locked = make(map[*OrderedMap]int)
+	}
+	pair := [2]*OrderedMap{x, y}
+	c.comparing[pair] = true
+
+	var unlock func()
+	switch {
+	case c.locked[x] > 0 && c.locked[y] > 0:
+		unlock = func() {}
+	case c.locked[x] > 0:
+		y.rlock()
+		unlock = y.runlock
+	case c.locked[y] > 0 || x == y:
+		x.rlock()
+		unlock = x.runlock
+	default:
+		unlock = rlockPair(x, y)
+	}
+	c.locked[x]++
+	c.locked[y]++
+
+	return func() {
+		c.locked[x]--
+		c.locked[y]--
+		unlock()
+		delete(c.comparing, pair)
+	}
+}
+
+// Change describes a key whose value differs between two maps.
+type Change struct {
+	Key      any
+	OldValue any
+	NewValue any
+}
+
+// Move describes a key whose position changed relative to the other keys.
+// From and To are zero-based positions in the old and the new map.
+type Move struct {
+	Key  any
+	From int
+	To   int
+}
+
+// Diff is the result of Compare.
+type Diff struct {
+	Added   []Pair   // Keys only in the new map, in its order
+	Removed []Pair   // Keys only in the old map, in its order
+	Changed []Change // Keys in both maps with different values, in the new map's order
+	Moved   []Move   // Keys in both maps that changed position, in the new map's order
+}
+
+// Empty reports whether the maps compared equal, including their order.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 && len(d.Moved) == 0
+}
+
+// String returns a human-readable report with one line per difference.
+//
+// Example:
+//
+//	fmt.Print(Compare(oldConfig, newConfig))
+//	// Output:
+//	// - legacy: true
+//	// + timeout: 30
+//	// ~ retries: 3 -> 5
+//	// key port moved from position 3 to 7
+func (d Diff) String() string {
+	var sb strings.Builder
+	for _, p := range d.Removed {
+		fmt.Fprintf(&sb, "- %v: %v\n", p.Key, p.Value)
+	}
+	for _, p := range d.Added {
+		fmt.Fprintf(&sb, "+ %v: %v\n", p.Key, p.Value)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&sb, "~ %v: %v -> %v\n", c.Key, c.OldValue, c.NewValue)
+	}
+	for _, m := range d.Moved {
+		fmt.Fprintf(&sb, "key %v moved from position %d to %d\n", m.Key, m.From, m.To)
+	}
+	return sb.String()
+}
+
+// Compare reports how newMap differs from oldMap: added, removed and changed
+// keys, and keys that moved. Moves are minimal: the longest sequence of
+// common keys whose relative order is unchanged stays put, and only the
+// remaining common keys are reported as moved. Values are compared as in
+// Equal. This function is thread-safe.
+//
+// Example:
+//
+//	diff := Compare(savedConfig, currentConfig)
+//	if !diff.Empty() {
+//	    fmt.Print(diff)
+//	}
+func Compare(oldMap, newMap *OrderedMap) Diff {
+	var c comparer
+	defer c.enter(oldMap, newMap)()
+
+	var d Diff
+	oldPos := make(map[any]int, oldMap.length)
+	i := 0
+	for current := oldMap.head; current != nil; current = current.next {
+		oldPos[current.Key] = i
+		if _, exists := newMap.nodeMap[current.Key]; !exists {
+			d.Removed = append(d.Removed, Pair{current.Key, current.Value})
+		}
+		i++
+	}
+
+	// Common keys in the new map's order, with their positions in both maps.
+	type common struct {
+		key      any
+		from, to int
+	}
+	var commons []common
+	j := 0
+	for current := newMap.head; current != nil; current = current.next {
+		from, exists := oldPos[current.Key]
+		if !exists {
+			d.Added = append(d.Added, Pair{current.Key, current.Value})
+		} else {
+			old := oldMap.nodeMap[current.Key]
+			if !c.values(old.Value, current.Value) {
+				d.Changed = append(d.Changed, Change{current.Key, old.Value, current.Value})
+			}
+			commons = append(commons, common{current.Key, from, j})
+		}
+		j++
+	}
+
+	// The common keys are a permutation of each other in both maps, so their
+	// longest common subsequence is the longest increasing run of old
+	// positions taken in new order.
+	from := make([]int, len(commons))
+	for k, c := range commons {
+		from[k] = c.from
+	}
+	stay := longestIncreasing(from)
+	for k, c := range commons {
+		if !stay[k] {
+			d.Moved = append(d.Moved, Move{Key: c.key, From: c.from, To: c.to})
+		}
+	}
+	return d
+}
+
+// longestIncreasing marks the elements of one longest strictly increasing
+// subsequence of seq, in O(n log n).
+func longestIncreasing(seq []int) []bool {
+	tails := []int{}              // tails[l] = index in seq ending the best run of length l+1
+	prev := make([]int, len(seq)) // previous index in the run ending at i
+	for i, v := range seq {
+		l := sort.Search(len(tails), func(k int) bool { return seq[tails[k]] >= v })
+		if l > 0 {
+			prev[i] = tails[l-1]
+		} else {
+			prev[i] = -1
+		}
+		if l == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[l] = i
+		}
+	}
+
+	marked := make([]bool, len(seq))
+	if len(tails) == 0 {
+		return marked
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+		marked[i] = true
+	}
+	return marked
+}
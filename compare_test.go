@@ -0,0 +1,152 @@
+package orderedmap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOrderedMap_Equal(t *testing.T) {
+	a := NewOrderedMap()
+	a.Set("x", 1)
+	a.Set("y", []any{1, 2})
+	b := a.Copy()
+
+	if !a.Equal(b) || !a.Equal(a) {
+		t.Error("Expected equal maps")
+	}
+	b.MoveToFront("y")
+	if a.Equal(b) {
+		t.Error("Expected order to matter for Equal")
+	}
+	if !a.EqualUnordered(b) {
+		t.Error("Expected EqualUnordered to ignore order")
+	}
+	b.Set("y", []any{1, 3})
+	if a.EqualUnordered(b) {
+		t.Error("Expected differing values to be unequal")
+	}
+	b.Set("z", 0)
+	if a.Equal(b) || a.EqualUnordered(b) {
+		t.Error("Expected maps of different length to be unequal")
+	}
+
+	t.Run("Nested", func(t *testing.T) {
+		n1, n2 := NewOrderedMap(), NewOrderedMap()
+		n1.Set("k", "v")
+		n2.Set("k", "v")
+		a, b := NewOrderedMap(), NewOrderedMap()
+		a.Set("nested", n1)
+		b.Set("nested", n2)
+		if !a.Equal(b) {
+			t.Error("Expected nested maps to be compared by content")
+		}
+		n2.Set("k2", "v2")
+		if a.Equal(b) {
+			t.Error("Expected nested difference to be detected")
+		}
+	})
+
+	t.Run("Cyclic", func(t *testing.T) {
+		a, b := NewOrderedMap(), NewOrderedMap()
+		a.Set("self", a)
+		a.Set("n", 1)
+		b.Set("self", b)
+		b.Set("n", 1)
+		if !a.Equal(b) || !a.EqualUnordered(b) || !Compare(a, b).Empty() {
+			t.Error("Expected self-referencing maps with equal contents to be equal")
+		}
+		b.Set("n", 2)
+		if a.Equal(b) || a.EqualUnordered(b) {
+			t.Error("Expected self-referencing maps with different contents to differ")
+		}
+		if d := Compare(a, b); len(d.Changed) != 1 || d.Changed[0].Key != "n" {
+			t.Errorf("Expected only n to change, got %v", d.Changed)
+		}
+
+		// a -> b -> a, compared with itself
+		a, b = NewOrderedMap(), NewOrderedMap()
+		a.Set("next", b)
+		b.Set("next", a)
+		if !a.Equal(a) {
+			t.Error("Expected a cyclic map to equal itself")
+		}
+	})
+
+	t.Run("EqualFunc", func(t *testing.T) {
+		a, b := NewOrderedMap(), NewOrderedMap()
+		a.Set("name", "Alice")
+		b.Set("name", "ALICE")
+		fold := func(x, y any) bool { return strings.EqualFold(x.(string), y.(string)) }
+		if a.Equal(b) || !a.EqualFunc(b, fold) {
+			t.Error("Expected EqualFunc to use the custom comparison")
+		}
+	})
+}
+
+func TestCompare(t *testing.T) {
+	oldMap := NewOrderedMap()
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		oldMap.Set(k, k)
+	}
+	newMap := oldMap.Copy()
+	newMap.Delete("b")
+	newMap.Set("f", "f")
+	newMap.Set("c", "C")
+	newMap.MoveToBack("a") // c d e f a
+
+	d := Compare(oldMap, newMap)
+	if !reflect.DeepEqual(d.Removed, []Pair{{"b", "b"}}) {
+		t.Errorf("Unexpected removed %v", d.Removed)
+	}
+	if !reflect.DeepEqual(d.Added, []Pair{{"f", "f"}}) {
+		t.Errorf("Unexpected added %v", d.Added)
+	}
+	if !reflect.DeepEqual(d.Changed, []Change{{"c", "c", "C"}}) {
+		t.Errorf("Unexpected changed %v", d.Changed)
+	}
+	if !reflect.DeepEqual(d.Moved, []Move{{Key: "a", From: 0, To: 4}}) {
+		t.Errorf("Unexpected moved %v", d.Moved)
+	}
+	if d.Empty() {
+		t.Error("Expected a non-empty diff")
+	}
+	expected := "- b: b\n+ f: f\n~ c: c -> C\nkey a moved from position 0 to 4\n"
+	if d.String() != expected {
+		t.Errorf("Expected report\n%s\ngot\n%s", expected, d.String())
+	}
+
+	if !Compare(oldMap, oldMap).Empty() || Compare(oldMap, oldMap).String() != "" {
+		t.Error("Expected an empty diff for the same map")
+	}
+}
+
+func TestCompare_MinimalMoves(t *testing.T) {
+	build := func(keys ...int) *OrderedMap {
+		om := NewOrderedMap()
+		for _, k := range keys {
+			om.Set(k, 0)
+		}
+		return om
+	}
+
+	tests := []struct {
+		name     string
+		old, new []int
+		moves    int
+	}{
+		{"Unchanged", []int{1, 2, 3}, []int{1, 2, 3}, 0},
+		{"Reversed", []int{1, 2, 3, 4}, []int{4, 3, 2, 1}, 3},
+		{"One Moved", []int{1, 2, 3, 4, 5, 6}, []int{1, 2, 5, 3, 4, 6}, 1},
+		{"Swap", []int{1, 2}, []int{2, 1}, 1},
+		{"Empty", nil, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Compare(build(tt.old...), build(tt.new...))
+			if len(d.Moved) != tt.moves {
+				t.Errorf("Expected %d moves, got %v", tt.moves, d.Moved)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package orderedmap
+
+// Cursor walks an OrderedMap in either direction, one entry at a time,
+// without copying the map.
+//
+// A cursor stays usable while the map is modified, including by other
+// goroutines. If the entry under the cursor is deleted, Key and Value keep
+// reporting it and Next and Prev continue with the entries that followed or
+// preceded it, even if other entries are moved in the meantime. Entries
+// inserted ahead of the cursor are visited when it gets there; if the entry
+// under the cursor is moved, the cursor moves with it. Only if the entries on
+// both sides of a deleted entry are moved away (for example by sorting the
+// map) before the cursor steps off it can Next and Prev no longer tell where
+// it was; they then return false.
+//
+// Every cursor method takes the map's lock for a single step only. A Cursor
+// itself must not be used by more than one goroutine at a time.
+//
+// Example:
+//
+//	c := om.Cursor()
+//	for ok := c.SeekFirst(); ok; ok = c.Next() {
+//	    fmt.Println(c.Key(), c.Value())
+//	}
+type Cursor struct {
+	om   *OrderedMap
+	node *Node
+}
+
+// Cursor returns a new cursor over the map. It is not positioned on any
+// entry until one of its Seek methods succeeds.
+// This method is thread-safe.
+func (om *OrderedMap) Cursor() *Cursor {
+	return &Cursor{om: om}
+}
+
+// Seek positions the cursor on key. Returns false and leaves the cursor
+// where it was if the key is nil or doesn't exist.
+//
+// Example:
+//
+//	// Resume a listing after the last key of the previous page
+//	c := om.Cursor()
+//	if c.Seek(lastKey) {
+//	    for len(page) < pageSize && c.Next() {
+//	        page = append(page, c.Key())
+//	    }
+//	}
+func (c *Cursor) Seek(key any) bool {
+	c.om.rlock()
+	defer c.om.runlock()
+
+	node, exists := c.om.lookup(key)
+	if !exists {
+		return false
+	}
+	c.node = node
+	return true
+}
+
+// SeekFirst positions the cursor on the first entry. Returns false if the
+// map is empty.
+func (c *Cursor) SeekFirst() bool {
+	c.om.rlock()
+	defer c.om.runlock()
+
+	if c.om.head == nil {
+		return false
+	}
+	c.node = c.om.head
+	return true
+}
+
+// SeekLast positions the cursor on the last entry. Returns false if the map
+// is empty.
+func (c *Cursor) SeekLast() bool {
+	c.om.rlock()
+	defer c.om.runlock()
+
+	if c.om.tail == nil {
+		return false
+	}
+	c.node = c.om.tail
+	return true
+}
+
+// Next advances the cursor to the next entry. Returns false and leaves the
+// cursor where it was if there is no next entry or the cursor is not
+// positioned; calling Next again later picks up entries appended since.
+func (c *Cursor) Next() bool {
+	c.om.rlock()
+	defer c.om.runlock()
+
+	if c.node == nil {
+		return false
+	}
+	next := c.node.next
+	if !c.om.alive(c.node) {
+		next = c.om.successor(c.node)
+	}
+	if next == nil {
+		return false
+	}
+	c.node = next
+	return true
+}
+
+// Prev moves the cursor to the previous entry. Returns false and leaves the
+// cursor where it was if there is no previous entry or the cursor is not
+// positioned.
+func (c *Cursor) Prev() bool {
+	c.om.rlock()
+	defer c.om.runlock()
+
+	if c.node == nil {
+		return false
+	}
+	prev := c.node.prev
+	if !c.om.alive(c.node) {
+		prev = c.om.predecessor(c.node)
+	}
+	if prev == nil {
+		return false
+	}
+	c.node = prev
+	return true
+}
+
+// Valid reports whether the cursor is positioned on an entry. The entry may
+// have been deleted since.
+func (c *Cursor) Valid() bool {
+	return c.node != nil
+}
+
+// Key returns the key under the cursor, or nil if the cursor is not
+// positioned.
+func (c *Cursor) Key() any {
+	if c.node == nil {
+		return nil
+	}
+	return c.node.Key
+}
+
+// Value returns the value under the cursor, or nil if the cursor is not
+// positioned. If the entry has been deleted, its last value is returned.
+func (c *Cursor) Value() any {
+	if c.node == nil {
+		return nil
+	}
+	c.om.rlock()
+	defer c.om.runlock()
+	return c.node.Value
+}
+
+// Delete removes the entry under the cursor from the map. The cursor stays
+// in place, so Next and Prev continue with its former neighbors. Returns
+// false if the cursor is not positioned or the entry is already gone.
+//
+// Example:
+//
+//	c := om.Cursor()
+//	for ok := c.SeekFirst(); ok; ok = c.Next() {
+//	    if c.Value() == nil {
+//	        c.Delete()
+//	    }
+//	}
+func (c *Cursor) Delete() bool {
+	if c.node == nil {
+		return false
+	}
+	c.om.lock()
+	defer c.om.unlock()
+
+	if !c.om.alive(c.node) {
+		return false
+	}
+	c.om.remove(c.node)
+	return true
+}
+
+// alive reports whether node is part of the map. The caller must hold om.mu.
+func (om *OrderedMap) alive(node *Node) bool {
+	return om.nodeMap[node.Key] == node
+}
+
+// successor returns the live node that now follows the place where the
+// removed node dead used to be, or nil if there is none or the place can no
+// longer be found. The caller must hold om.mu.
+func (om *OrderedMap) successor(dead *Node) *Node {
+	// Whatever now follows the nearest unmoved entry before dead is next,
+	// including entries inserted since.
+	if anchor, ok := om.anchor(dead, func(n *Node) *Node { return n.prev }); ok {
+		if anchor == nil {
+			return om.head
+		}
+		return anchor.next
+	}
+	// The entries before dead were moved; the nearest unmoved entry after it
+	// is next.
+	anchor, _ := om.anchor(dead, func(n *Node) *Node { return n.next })
+	return anchor
+}
+
+// predecessor is the mirror image of successor.
+func (om *OrderedMap) predecessor(dead *Node) *Node {
+	if anchor, ok := om.anchor(dead, func(n *Node) *Node { return n.next }); ok {
+		if anchor == nil {
+			return om.tail
+		}
+		return anchor.prev
+	}
+	anchor, _ := om.anchor(dead, func(n *Node) *Node { return n.prev })
+	return anchor
+}
+
+// anchor follows the pointers that the removed node dead kept from the time
+// it was removed, skipping nodes that were removed after it, to the nearest
+// live node that has stayed in place since. It returns nil and true if the
+// walk reaches the end of the list, and false if it runs into a node that
+// was relinked since, whose pointers no longer describe the old order.
+// The caller must hold om.mu.
+func (om *OrderedMap) anchor(dead *Node, step func(*Node) *Node) (*Node, bool) {
+	if dead.stamp < om.cleared {
+		// Dropped by Clear along with all of its neighbors.
+		return nil, false
+	}
+	for n := step(dead); n != nil; n = step(n) {
+		switch {
+		case om.alive(n) && n.stamp < dead.stamp:
+			return n, true
+		case !om.alive(n) && n.stamp > dead.stamp:
+			// Removed later than dead, so its pointers are more recent.
+			dead = n
+		default:
+			return nil, false
+		}
+	}
+	return nil, true
+}
@@ -0,0 +1,260 @@
+package orderedmap
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestOrderedMap_Cursor(t *testing.T) {
+	newMap := func() *OrderedMap {
+		om := NewOrderedMap()
+		for i := 0; i < 5; i++ {
+			om.Set(i, i*10)
+		}
+		return om
+	}
+
+	t.Run("Walk", func(t *testing.T) {
+		om := newMap()
+		c := om.Cursor()
+		if c.Valid() || c.Next() || c.Prev() || c.Key() != nil || c.Value() != nil || c.Delete() {
+			t.Error("Expected an unpositioned cursor to do nothing")
+		}
+
+		var forward []any
+		for ok := c.SeekFirst(); ok; ok = c.Next() {
+			forward = append(forward, c.Key())
+		}
+		var backward []any
+		for ok := c.SeekLast(); ok; ok = c.Prev() {
+			backward = append(backward, c.Value())
+		}
+		if !reflect.DeepEqual(forward, []any{0, 1, 2, 3, 4}) {
+			t.Errorf("Unexpected forward walk %v", forward)
+		}
+		if !reflect.DeepEqual(backward, []any{40, 30, 20, 10, 0}) {
+			t.Errorf("Unexpected backward walk %v", backward)
+		}
+		if c.Key() != 0 {
+			t.Errorf("Expected cursor to stay on the first entry, got %v", c.Key())
+		}
+
+		empty := NewOrderedMap().Cursor()
+		if empty.SeekFirst() || empty.SeekLast() {
+			t.Error("Expected seeking an empty map to fail")
+		}
+	})
+
+	t.Run("Seek", func(t *testing.T) {
+		om := newMap()
+		c := om.Cursor()
+		if !c.Seek(2) || c.Value() != 20 {
+			t.Errorf("Expected cursor on 2, got %v", c.Key())
+		}
+		if c.Seek("missing") || c.Seek(nil) || c.Key() != 2 {
+			t.Error("Expected failed seek to leave the cursor in place")
+		}
+
+		// Resumable pagination.
+		var page []any
+		for len(page) < 2 && c.Next() {
+			page = append(page, c.Key())
+		}
+		if !reflect.DeepEqual(page, []any{3, 4}) {
+			t.Errorf("Unexpected page %v", page)
+		}
+		if c.Next() {
+			t.Error("Expected Next at the end to fail")
+		}
+		om.Set(5, 50)
+		if !c.Next() || c.Key() != 5 {
+			t.Error("Expected Next to pick up an appended entry")
+		}
+	})
+
+	t.Run("Delete Under Cursor", func(t *testing.T) {
+		om := newMap()
+		c := om.Cursor()
+		for ok := c.SeekFirst(); ok; ok = c.Next() {
+			if c.Key().(int)%2 == 0 {
+				if !c.Delete() {
+					t.Errorf("Expected Delete of %v to succeed", c.Key())
+				}
+				if c.Delete() {
+					t.Error("Expected second Delete to fail")
+				}
+			}
+		}
+		if !reflect.DeepEqual(om.Keys(), []any{1, 3}) {
+			t.Errorf("Unexpected keys %v", om.Keys())
+		}
+	})
+
+	t.Run("Concurrent Deletes Around Cursor", func(t *testing.T) {
+		om := newMap()
+		c := om.Cursor()
+		c.Seek(2)
+
+		om.Delete(2)
+		om.Delete(3)
+		if c.Key() != 2 || c.Value() != 20 {
+			t.Error("Expected cursor to keep reporting the deleted entry")
+		}
+		if !c.Next() || c.Key() != 4 {
+			t.Errorf("Expected Next to skip deleted entries, got %v", c.Key())
+		}
+
+		c.Seek(1)
+		om.Delete(1)
+		om.Delete(0)
+		if c.Prev() {
+			t.Error("Expected Prev to fail once all earlier entries are gone")
+		}
+		if !c.Next() || c.Key() != 4 {
+			t.Errorf("Expected Next from a deleted entry to reach 4, got %v", c.Key())
+		}
+	})
+
+	t.Run("Inserts And Moves", func(t *testing.T) {
+		om := newMap()
+		c := om.Cursor()
+		c.Seek(1)
+		om.Set(9, 90)
+		om.MoveAfter(om.GetElement(9), om.GetElement(1))
+		if !c.Next() || c.Key() != 9 {
+			t.Errorf("Expected Next to visit the inserted entry, got %v", c.Key())
+		}
+		om.MoveToBack(9)
+		if !c.Prev() || c.Key() != 4 {
+			t.Errorf("Expected cursor to move with its entry, got %v", c.Key())
+		}
+	})
+
+	t.Run("Deleted Then Neighbor Moved", func(t *testing.T) {
+		om := NewOrderedMap()
+		for _, k := range []string{"a", "b", "c", "d"} {
+			om.Set(k, k)
+		}
+		c := om.Cursor()
+		c.Seek("b")
+		om.Delete("b")
+		om.MoveToBack("c")
+
+		var visited []any
+		for c.Next() {
+			visited = append(visited, c.Key())
+		}
+		if !reflect.DeepEqual(visited, []any{"d", "c"}) {
+			t.Errorf("Expected [d c], got %v", visited)
+		}
+
+		c.Seek("d")
+		om.Delete("d")
+		om.MoveToFront("a")
+		if !c.Prev() || c.Key() != "a" {
+			t.Errorf("Expected Prev to reach a, got %v", c.Key())
+		}
+	})
+
+	t.Run("After ReadSnapshot", func(t *testing.T) {
+		src := NewOrderedMap()
+		for _, k := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+			src.Set(k, k)
+		}
+		var buf bytes.Buffer
+		if err := src.WriteSnapshot(&buf); err != nil {
+			t.Fatal(err)
+		}
+		om := NewOrderedMap()
+		if err := om.ReadSnapshot(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		c := om.Cursor()
+		c.Seek("e")
+		om.Delete("e")
+		if !c.Next() || c.Key() != "f" {
+			t.Errorf("Expected Next to reach f, got %v", c.Key())
+		}
+		c.Seek("f")
+		om.Delete("f")
+		if !c.Prev() || c.Key() != "d" {
+			t.Errorf("Expected Prev to reach d, got %v", c.Key())
+		}
+	})
+
+	t.Run("Deleted Then Sorted", func(t *testing.T) {
+		om := NewOrderedMap()
+		for _, k := range []int{3, 1, 2} {
+			om.Set(k, k)
+		}
+		c := om.Cursor()
+		c.Seek(1)
+		om.Delete(1)
+		om.SortKeys()
+		// Both neighbors were relinked, so the old position is lost.
+		if c.Next() {
+			t.Errorf("Expected Next to stop, got %v", c.Key())
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		om := newMap()
+		c := om.Cursor()
+		c.Seek(2)
+		om.Clear()
+		om.Set("new", 1)
+		if c.Next() || c.Prev() || c.Delete() {
+			t.Error("Expected cursor over a cleared map to find nothing")
+		}
+		if !c.SeekFirst() || c.Key() != "new" {
+			t.Error("Expected cursor to be reusable after Clear")
+		}
+	})
+}
+
+func TestOrderedMap_CursorConcurrent(t *testing.T) {
+	om := NewOrderedMap()
+	for i := 0; i < 200; i++ {
+		om.Set(i, i)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i += 2 {
+			om.Delete(i)
+			om.Set(1000+i, i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for round := 0; round < 5; round++ {
+			c := om.Cursor()
+			last := -1
+			for ok := c.SeekFirst(); ok; ok = c.Next() {
+				k := c.Key().(int)
+				if k < 1000 && k <= last {
+					t.Errorf("Cursor went backwards: %d after %d", k, last)
+					return
+				}
+				if k < 1000 {
+					last = k
+				}
+			}
+		}
+	}()
+	wg.Wait()
+
+	c := om.Cursor()
+	n := 0
+	for ok := c.SeekFirst(); ok; ok = c.Next() {
+		n++
+	}
+	if n != om.Len() {
+		t.Errorf("Expected to visit %d entries, got %d", om.Len(), n)
+	}
+}
@@ -0,0 +1,158 @@
+package orderedmap
+
+import (
+	"context"
+	"fmt"
+)
+
+// PopFront removes and returns the first key-value pair. Returns false if
+// the map is empty. Unlike First followed by Delete, this is a single atomic
+// step, so concurrent consumers never receive the same entry.
+// This method is thread-safe.
+//
+// Example:
+//
+//	for {
+//	    job, payload, ok := queue.PopFront()
+//	    if !ok {
+//	        break
+//	    }
+//	    process(job, payload)
+//	}
+func (om *OrderedMap) PopFront() (key, value any, exists bool) {
+	om.lock()
+	defer om.unlock()
+	return om.pop(om.head)
+}
+
+// PopBack removes and returns the last key-value pair. Returns false if the
+// map is empty. This method is thread-safe.
+//
+// Example:
+//
+//	key, value, ok := om.PopBack()
+func (om *OrderedMap) PopBack() (key, value any, exists bool) {
+	om.lock()
+	defer om.unlock()
+	return om.pop(om.tail)
+}
+
+// PopFrontWait is like PopFront but blocks until the map has an entry to
+// return. If ctx is done first, it returns ctx.Err().
+// This method is thread-safe.
+//
+// Example:
+//
+//	for {
+//	    job, payload, err := queue.PopFrontWait(ctx)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    process(job, payload)
+//	}
+func (om *OrderedMap) PopFrontWait(ctx context.Context) (key, value any, err error) {
+	for {
+		om.lock()
+		key, value, exists := om.pop(om.head)
+		var changed <-chan struct{}
+		if !exists {
+			changed = om.changed()
+		}
+		om.unlock()
+
+		if exists {
+			return key, value, nil
+		}
+		if err := om.waitChange(ctx, changed); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// PeekFront returns the first key-value pair without removing it.
+// It is the same as First. This method is thread-safe.
+func (om *OrderedMap) PeekFront() (key, value any, exists bool) {
+	return om.First()
+}
+
+// PeekBack returns the last key-value pair without removing it.
+// It is the same as Last. This method is thread-safe.
+func (om *OrderedMap) PeekBack() (key, value any, exists bool) {
+	return om.Last()
+}
+
+// PushFront adds a key-value pair at the front of the map. If the key
+// already exists, its value is updated and it is moved to the front, which
+// makes PushFront suitable for re-queueing an entry with priority. If the
+// map was created with WithMaxLen and is full, the back entry is evicted.
+// Returns an error if the key is nil. This method is thread-safe.
+//
+// Example:
+//
+//	queue.PushFront("job-7", retryPayload)
+func (om *OrderedMap) PushFront(key, value any) error {
+	if key == nil {
+		return fmt.Errorf("key cannot be nil")
+	}
+
+	om.lock()
+	defer om.unlock()
+
+	key = om.normalizeKey(key)
+	if key == nil {
+		return fmt.Errorf("key cannot be nil")
+	}
+	if node, exists := om.nodeMap[key]; exists {
+		// key is already normalized; set would normalize it again.
+		if err := om.setNormalized(key, value); err != nil {
+			return err
+		}
+		om.moveAfter(node, nil)
+		return nil
+	}
+
+	om.prepareWrite()
+	node := &Node{Key: key, Value: value}
+	om.pushFront(node)
+	om.nodeMap[key] = node
+	om.length++
+	om.emit(Event{Type: EventInserted, Key: key, Value: value})
+	for om.maxLen > 0 && om.length > om.maxLen {
+		om.remove(om.tail)
+	}
+	return nil
+}
+
+// PushBack adds a key-value pair at the back of the map. If the key already
+// exists, its value is updated and it is moved to the back. Returns an error
+// if the key is nil. This method is thread-safe.
+//
+// Example:
+//
+//	queue.PushBack("job-8", payload)
+func (om *OrderedMap) PushBack(key, value any) error {
+	if key == nil {
+		return fmt.Errorf("key cannot be nil")
+	}
+
+	om.lock()
+	defer om.unlock()
+
+	if err := om.set(key, value); err != nil {
+		return err
+	}
+	if node, exists := om.lookup(key); exists {
+		om.moveAfter(node, om.tail)
+	}
+	return nil
+}
+
+// pop removes node and returns its pair, or false if node is nil. The caller
+// must hold om.mu for writing.
+func (om *OrderedMap) pop(node *Node) (key, value any, exists bool) {
+	if node == nil {
+		return nil, nil, false
+	}
+	om.remove(node)
+	return node.Key, node.Value, true
+}
@@ -0,0 +1,166 @@
+package orderedmap
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOrderedMap_Deque(t *testing.T) {
+	t.Run("Pop", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set("a", 1)
+		om.Set("b", 2)
+		om.Set("c", 3)
+
+		if k, v, ok := om.PopFront(); !ok || k != "a" || v != 1 {
+			t.Errorf("Unexpected PopFront (%v, %v, %v)", k, v, ok)
+		}
+		if k, v, ok := om.PopBack(); !ok || k != "c" || v != 3 {
+			t.Errorf("Unexpected PopBack (%v, %v, %v)", k, v, ok)
+		}
+		if k, _, _ := om.PeekFront(); k != "b" {
+			t.Errorf("Expected front b, got %v", k)
+		}
+		if k, _, _ := om.PeekBack(); k != "b" {
+			t.Errorf("Expected back b, got %v", k)
+		}
+		om.PopFront()
+		if _, _, ok := om.PopFront(); ok {
+			t.Error("Expected PopFront on empty map to fail")
+		}
+		if _, _, ok := om.PopBack(); ok {
+			t.Error("Expected PopBack on empty map to fail")
+		}
+	})
+
+	t.Run("Push", func(t *testing.T) {
+		om := NewOrderedMap()
+		if err := om.PushFront(nil, 1); err == nil {
+			t.Error("Expected error for nil key")
+		}
+		if err := om.PushBack(nil, 1); err == nil {
+			t.Error("Expected error for nil key")
+		}
+		om.PushBack("a", 1)
+		om.PushFront("b", 2)
+		om.PushBack("c", 3)
+		if om.String() != "{b: 2, a: 1, c: 3}" {
+			t.Errorf("Unexpected state %s", om.String())
+		}
+
+		// Re-queue existing entries.
+		om.PushFront("c", 30)
+		om.PushBack("b", 20)
+		if om.String() != "{c: 30, a: 1, b: 20}" {
+			t.Errorf("Unexpected state %s", om.String())
+		}
+		if om.Len() != 3 {
+			t.Errorf("Expected 3 elements, got %d", om.Len())
+		}
+	})
+
+	t.Run("Push With MaxLen", func(t *testing.T) {
+		om := New(WithMaxLen(2))
+		om.PushBack("a", 1)
+		om.PushBack("b", 2)
+		om.PushFront("urgent", 0)
+		if !reflect.DeepEqual(om.Keys(), []any{"urgent", "a"}) {
+			t.Errorf("Expected the back entry to be evicted, got %v", om.Keys())
+		}
+	})
+
+	t.Run("Push With Normalizer", func(t *testing.T) {
+		om := New(WithKeyNormalizer(func(key any) any { return "x" + key.(string) }))
+		om.PushBack("a", 1)
+		om.PushBack("b", 2)
+		om.PushFront("b", 3)
+		if om.String() != "{xb: 3, xa: 1}" {
+			t.Errorf("Expected {xb: 3, xa: 1}, got %s", om.String())
+		}
+	})
+
+	t.Run("Events", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set("a", 1)
+		var events []EventType
+		om.OnChange(func(ev Event) { events = append(events, ev.Type) })
+		om.PushFront("b", 2)
+		om.PushFront("a", 10)
+		om.PopBack()
+		expected := []EventType{EventInserted, EventUpdated, EventMoved, EventDeleted}
+		if !reflect.DeepEqual(events, expected) {
+			t.Errorf("Expected %v, got %v", expected, events)
+		}
+	})
+}
+
+func TestOrderedMap_PopFrontWait(t *testing.T) {
+	t.Run("Ready", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set("a", 1)
+		k, v, err := om.PopFrontWait(context.Background())
+		if err != nil || k != "a" || v != 1 || om.Len() != 0 {
+			t.Errorf("Unexpected (%v, %v, %v)", k, v, err)
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, _, err := NewOrderedMap().PopFrontWait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("Consumers", func(t *testing.T) {
+		om := NewOrderedMap()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		const n = 100
+		var mu sync.Mutex
+		seen := make(map[any]int)
+		var wg sync.WaitGroup
+		for c := 0; c < 4; c++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					k, _, err := om.PopFrontWait(ctx)
+					if err != nil {
+						t.Errorf("Unexpected error: %v", err)
+						return
+					}
+					if k == "stop" {
+						return
+					}
+					mu.Lock()
+					seen[k]++
+					mu.Unlock()
+				}
+			}()
+		}
+
+		for i := 0; i < n; i++ {
+			om.Set(i, i)
+		}
+		for i := 0; i < 4; i++ {
+			om.WaitUntil(ctx, func(m *OrderedMap) bool { return !m.Has("stop") })
+			om.Set("stop", true)
+		}
+		wg.Wait()
+
+		if len(seen) != n {
+			t.Errorf("Expected %d distinct entries, got %d", n, len(seen))
+		}
+		for k, count := range seen {
+			if count != 1 {
+				t.Errorf("Entry %v was received %d times", k, count)
+			}
+		}
+	})
+}
@@ -0,0 +1,735 @@
+package orderedmap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Write-ahead log format
+//
+// A log file starts with an 8 byte header: the magic "OWAL", a big endian
+// uint16 format version (currently 1) and two reserved zero bytes. It is
+// followed by records:
+//
+//	size  field
+//	4     payload length (big endian)
+//	4     CRC-32 (IEEE) of the payload (big endian)
+//	...   payload
+//
+// A payload is an operation byte followed by the operation's arguments, each
+// written as a uvarint length and the Codec encoding of the argument.
+//
+// A durable map directory holds at most one live generation: "snapshot.N"
+// (absent for generation 0) and "wal.N". Compaction writes "snapshot.N+1",
+// starts an empty "wal.N+1" and only then removes the files of generation N,
+// so a crash at any point leaves a directory that reopens to the same state.
+
+const (
+	walMagic      = "OWAL"
+	walVersion    = 1
+	walHeaderSize = 8
+
+	// walRecordHeaderSize is the size of the length and checksum prefix of a record.
+	walRecordHeaderSize = 8
+
+	// maxWALRecord bounds the payload size of a single record.
+	maxWALRecord = 1 << 30
+
+	// DefaultCompactThreshold is the log size in bytes that triggers compaction
+	// when DurableOptions.CompactThreshold is zero.
+	DefaultCompactThreshold = 64 << 20
+)
+
+// Log operations. The numeric values are part of the on-disk format.
+const (
+	walOpSet byte = iota + 1
+	walOpDelete
+	walOpClear
+	walOpMoveToFront
+	walOpMoveToBack
+)
+
+var (
+	// ErrClosed is returned by mutating methods of a DurableOrderedMap after Close.
+	ErrClosed = errors.New("durable map is closed")
+	// ErrCorruptLog is returned by OpenDurable when a record in the middle of
+	// the log is damaged. The log file is left untouched.
+	ErrCorruptLog = errors.New("corrupt write-ahead log")
+)
+
+// SyncPolicy controls when a DurableOrderedMap flushes its log to stable storage.
+type SyncPolicy int
+
+const (
+	// SyncAlways calls fsync after every appended record. A mutation that
+	// returned successfully survives a power failure; if fsync fails, the
+	// record is removed from the log and the mutation is not applied.
+	SyncAlways SyncPolicy = iota
+	// SyncInterval calls fsync when at least DurableOptions.SyncInterval has
+	// elapsed since the previous fsync, on the next write or from a
+	// background goroutine once writes stop. Records always reach the
+	// operating system, so they survive a process crash, but up to about two
+	// intervals of writes may be lost on power failure.
+	SyncInterval
+	// SyncNever leaves flushing to the operating system.
+	SyncNever
+)
+
+// DurableOptions represents configuration options for OpenDurable.
+type DurableOptions struct {
+	// Codec encodes keys and values in the log and snapshots. Defaults to BinaryCodec.
+	Codec Codec
+	// Sync selects the fsync policy. Defaults to SyncAlways.
+	Sync SyncPolicy
+	// SyncInterval is the minimum time between fsyncs under SyncInterval.
+	// Defaults to one second.
+	SyncInterval time.Duration
+	// CompactThreshold is the log size in bytes above which the log is folded
+	// into a new snapshot. Zero selects DefaultCompactThreshold and a negative
+	// value disables automatic compaction.
+	CompactThreshold int64
+}
+
+// DurableOrderedMap is an OrderedMap whose mutations are appended to a
+// write-ahead log before they are applied, so the exact contents and order of
+// the map can be reconstructed after a restart or crash.
+//
+// Reads are served from memory and do not touch the disk. Writes are
+// serialized; each one is logged, optionally fsynced, and then applied.
+type DurableOrderedMap struct {
+	mu       sync.Mutex // Serializes writers and guards the fields below
+	om       *OrderedMap
+	dir      string
+	opts     DurableOptions
+	gen      uint64
+	log      *os.File
+	logSize  int64
+	lastSync time.Time
+	dirty    bool          // Records were written since the last fsync
+	stop     chan struct{} // Closed by Close to end the background flusher
+	buf      []byte
+	closed   bool
+}
+
+// OpenDurable opens the durable map stored in dir, creating the directory if
+// it doesn't exist. The latest snapshot is loaded and the log is replayed on
+// top of it. A partially written record at the end of the log (a torn write
+// from a crash) is discarded and truncated away; everything before it is kept.
+// A damaged record followed by more records is not a torn write: OpenDurable
+// returns an error wrapping ErrCorruptLog and leaves the file as it is.
+//
+// Example:
+//
+//	dm, err := OpenDurable("/var/lib/app/state", &DurableOptions{Sync: SyncInterval})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer dm.Close()
+//	dm.Set("key", "value")
+func OpenDurable(dir string, opts *DurableOptions) (*DurableOrderedMap, error) {
+	var o DurableOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Codec == nil {
+		o.Codec = BinaryCodec{}
+	}
+	if o.SyncInterval <= 0 {
+		o.SyncInterval = time.Second
+	}
+	if o.CompactThreshold == 0 {
+		o.CompactThreshold = DefaultCompactThreshold
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	gen, err := latestGeneration(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dm := &DurableOrderedMap{
+		om:       NewOrderedMap(),
+		dir:      dir,
+		opts:     o,
+		gen:      gen,
+		lastSync: time.Now(),
+	}
+
+	if gen > 0 {
+		f, err := os.Open(dm.snapshotPath(gen))
+		if err != nil {
+			return nil, err
+		}
+		err = dm.om.ReadSnapshotWithCodec(f, o.Codec)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("loading %s: %w", dm.snapshotPath(gen), err)
+		}
+	}
+
+	if err := dm.openLog(); err != nil {
+		return nil, err
+	}
+	removeOldGenerations(dir, gen)
+	if o.Sync == SyncInterval {
+		dm.stop = make(chan struct{})
+		go dm.flushLoop(dm.stop)
+	}
+	return dm, nil
+}
+
+// Set adds or updates a key-value pair and records it in the log.
+// Returns an error if the key is nil or unhashable or the log cannot be
+// written.
+func (dm *DurableOrderedMap) Set(key, value any) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	return dm.apply(walOpSet, []any{key, value}, func() { _ = dm.om.Set(key, value) })
+}
+
+// Delete removes a key and records the removal in the log.
+// Deleting a missing key is a no-op and is not logged.
+func (dm *DurableOrderedMap) Delete(key any) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	if !dm.om.Has(key) {
+		return nil
+	}
+	return dm.apply(walOpDelete, []any{key}, func() { _ = dm.om.Delete(key) })
+}
+
+// Clear removes all elements and records the operation in the log.
+func (dm *DurableOrderedMap) Clear() error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	return dm.apply(walOpClear, nil, dm.om.Clear)
+}
+
+// MoveToFront moves an existing key to the front of the map and records the
+// move in the log. Returns false if the key doesn't exist.
+func (dm *DurableOrderedMap) MoveToFront(key any) (bool, error) {
+	return dm.move(walOpMoveToFront, key, dm.om.MoveToFront)
+}
+
+// MoveToBack moves an existing key to the back of the map and records the
+// move in the log. Returns false if the key doesn't exist.
+func (dm *DurableOrderedMap) MoveToBack(key any) (bool, error) {
+	return dm.move(walOpMoveToBack, key, dm.om.MoveToBack)
+}
+
+func (dm *DurableOrderedMap) move(op byte, key any, fn func(any) bool) (bool, error) {
+	if key != nil && !isHashable(key) {
+		return false, fmt.Errorf("unhashable key type %T", key)
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	if !dm.om.Has(key) {
+		return false, nil
+	}
+	if err := dm.apply(op, []any{key}, func() { fn(key) }); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// checkKey rejects keys that the log would record but the map cannot hold,
+// so a bad call never reaches the log.
+func checkKey(key any) error {
+	if key == nil {
+		return fmt.Errorf("key cannot be nil")
+	}
+	if !isHashable(key) {
+		return fmt.Errorf("unhashable key type %T", key)
+	}
+	return nil
+}
+
+// Get retrieves the value associated with the given key.
+func (dm *DurableOrderedMap) Get(key any) (any, bool) { return dm.om.Get(key) }
+
+// Has checks if a key exists in the map.
+func (dm *DurableOrderedMap) Has(key any) bool { return dm.om.Has(key) }
+
+// Len returns the number of elements in the map.
+func (dm *DurableOrderedMap) Len() int { return dm.om.Len() }
+
+// Keys returns all keys in order.
+func (dm *DurableOrderedMap) Keys() []any { return dm.om.Keys() }
+
+// Values returns all values in order.
+func (dm *DurableOrderedMap) Values() []any { return dm.om.Values() }
+
+// Range iterates over the map in order. See OrderedMap.Range.
+func (dm *DurableOrderedMap) Range(f func(key, value any) bool) { dm.om.Range(f) }
+
+// First returns the first key-value pair in the map.
+func (dm *DurableOrderedMap) First() (key, value any, exists bool) { return dm.om.First() }
+
+// Last returns the last key-value pair in the map.
+func (dm *DurableOrderedMap) Last() (key, value any, exists bool) { return dm.om.Last() }
+
+// String returns a string representation of the map.
+func (dm *DurableOrderedMap) String() string { return dm.om.String() }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (dm *DurableOrderedMap) MarshalJSON() ([]byte, error) { return dm.om.MarshalJSON() }
+
+// Copy returns an in-memory copy of the current contents.
+func (dm *DurableOrderedMap) Copy() *OrderedMap { return dm.om.Copy() }
+
+// Sync flushes the log to stable storage regardless of the sync policy.
+func (dm *DurableOrderedMap) Sync() error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	if dm.closed {
+		return ErrClosed
+	}
+	return dm.syncLog()
+}
+
+// Compact writes the current contents to a new snapshot and starts an empty
+// log. It is called automatically when the log grows past
+// DurableOptions.CompactThreshold.
+func (dm *DurableOrderedMap) Compact() error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	if dm.closed {
+		return ErrClosed
+	}
+	return dm.compact()
+}
+
+// Close flushes and closes the log. Reads keep working on the in-memory
+// contents; writes return ErrClosed.
+func (dm *DurableOrderedMap) Close() error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	if dm.closed {
+		return nil
+	}
+	dm.closed = true
+	if dm.stop != nil {
+		close(dm.stop)
+	}
+	syncErr := dm.log.Sync()
+	if err := dm.log.Close(); err != nil {
+		return err
+	}
+	return syncErr
+}
+
+// apply logs an operation and then runs mutate to apply it in memory.
+// The caller must hold dm.mu.
+func (dm *DurableOrderedMap) apply(op byte, args []any, mutate func()) error {
+	if dm.closed {
+		return ErrClosed
+	}
+
+	if logged, err := dm.appendRecord(op, args); err != nil {
+		if logged {
+			// The record could not be removed and will be replayed, so the
+			// map must match it.
+			mutate()
+		}
+		return err
+	}
+	mutate()
+
+	if dm.opts.CompactThreshold > 0 && dm.logSize >= dm.opts.CompactThreshold {
+		// The mutation itself is already durable; a failed compaction is
+		// reported but leaves the log in place to be retried next time.
+		if err := dm.compact(); err != nil {
+			return fmt.Errorf("compacting log: %w", err)
+		}
+	}
+	return nil
+}
+
+// appendRecord writes one record to the log and syncs it as the sync policy
+// requires. If the write or the sync fails, the record is cut off again so it
+// is not replayed; logged reports whether it is still in the log because
+// that failed too.
+func (dm *DurableOrderedMap) appendRecord(op byte, args []any) (logged bool, err error) {
+	buf := append(dm.buf[:0], make([]byte, walRecordHeaderSize)...)
+	buf = append(buf, op)
+	for _, arg := range args {
+		data, err := dm.opts.Codec.Encode(arg)
+		if err != nil {
+			return false, err
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(data)))
+		buf = append(buf, data...)
+	}
+	payload := buf[walRecordHeaderSize:]
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	dm.buf = buf
+
+	n, err := dm.log.Write(buf)
+	if err != nil {
+		// Cut off whatever part of the record made it to the file so the
+		// next append doesn't follow a torn record.
+		if n > 0 {
+			_ = dm.log.Truncate(dm.logSize)
+			_, _ = dm.log.Seek(dm.logSize, io.SeekStart)
+		}
+		return false, err
+	}
+
+	dm.dirty = true
+	switch dm.opts.Sync {
+	case SyncAlways:
+		err = dm.syncLog()
+	case SyncInterval:
+		if time.Since(dm.lastSync) >= dm.opts.SyncInterval {
+			err = dm.syncLog()
+		}
+	}
+	if err != nil {
+		// The record may not be on disk, so the mutation fails. Remove the
+		// record so it doesn't come back after a restart.
+		if terr := dm.log.Truncate(dm.logSize); terr != nil {
+			dm.logSize += int64(n)
+			return true, err
+		}
+		_, _ = dm.log.Seek(dm.logSize, io.SeekStart)
+		return false, err
+	}
+	dm.logSize += int64(n)
+	return false, nil
+}
+
+func (dm *DurableOrderedMap) syncLog() error {
+	dm.lastSync = time.Now()
+	if err := dm.log.Sync(); err != nil {
+		return err
+	}
+	dm.dirty = false
+	return nil
+}
+
+// flushLoop syncs records that are still unsynced once SyncInterval has
+// elapsed, so they don't wait for the next write. It runs until stop is
+// closed.
+func (dm *DurableOrderedMap) flushLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(dm.opts.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+		dm.mu.Lock()
+		if !dm.closed && dm.dirty && time.Since(dm.lastSync) >= dm.opts.SyncInterval {
+			// A failure leaves the records dirty, so the next tick retries.
+			_ = dm.syncLog()
+		}
+		dm.mu.Unlock()
+	}
+}
+
+// openLog opens the log of the current generation, replays it into dm.om and
+// truncates any torn record at its end.
+func (dm *DurableOrderedMap) openLog() error {
+	f, err := os.OpenFile(dm.walPath(dm.gen), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	valid, err := dm.replay(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	if valid < walHeaderSize {
+		if err := writeWALHeader(f); err != nil {
+			f.Close()
+			return err
+		}
+		valid = walHeaderSize
+	}
+	if err := f.Truncate(valid); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Seek(valid, io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+
+	dm.log = f
+	dm.logSize = valid
+	return nil
+}
+
+// replay applies every intact record in f to dm.om and returns the offset just
+// past the last intact record. A short or corrupt record that runs to the end
+// of the file is a torn write and ends the log; a corrupt record followed by
+// more data is reported as ErrCorruptLog, since discarding it would lose the
+// intact records after it.
+func (dm *DurableOrderedMap) replay(f *os.File) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	fileSize := info.Size()
+	r := bufio.NewReader(f)
+
+	var header [walHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		// Empty or torn header: the log was never written to.
+		return 0, nil
+	}
+	if string(header[:4]) != walMagic {
+		return 0, fmt.Errorf("%s: not a write-ahead log", f.Name())
+	}
+	if version := binary.BigEndian.Uint16(header[4:6]); version != walVersion {
+		return 0, fmt.Errorf("%s: unsupported log version %d", f.Name(), version)
+	}
+
+	offset := int64(walHeaderSize)
+	var recHeader [walRecordHeaderSize]byte
+	for {
+		if _, err := io.ReadFull(r, recHeader[:]); err != nil {
+			return offset, nil
+		}
+		size := binary.BigEndian.Uint32(recHeader[0:4])
+		if size == 0 || size > maxWALRecord {
+			// The extent of the record is unknown. Only a zero-filled tail,
+			// as left by a crash after the file was extended, is torn.
+			if recHeader == [walRecordHeaderSize]byte{} && onlyZeros(r) {
+				return offset, nil
+			}
+			return 0, fmt.Errorf("%s: %w: invalid record length %d at offset %d", f.Name(), ErrCorruptLog, size, offset)
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return offset, nil
+		}
+		end := offset + walRecordHeaderSize + int64(size)
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(recHeader[4:8]) {
+			if end >= fileSize {
+				return offset, nil
+			}
+			return 0, fmt.Errorf("%s: %w: checksum mismatch in record at offset %d", f.Name(), ErrCorruptLog, offset)
+		}
+		if err := dm.replayRecord(payload); err != nil {
+			return 0, fmt.Errorf("%s: record at offset %d: %w", f.Name(), offset, err)
+		}
+		offset = end
+	}
+}
+
+// onlyZeros reports whether everything left in r is zero bytes.
+func onlyZeros(r io.Reader) bool {
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			if b != 0 {
+				return false
+			}
+		}
+		if err != nil {
+			return err == io.EOF
+		}
+	}
+}
+
+func (dm *DurableOrderedMap) replayRecord(payload []byte) error {
+	op, rest := payload[0], payload[1:]
+	var args []any
+	for len(rest) > 0 {
+		data, tail, err := readBytes(rest)
+		if err != nil {
+			return err
+		}
+		arg, err := dm.opts.Codec.Decode(data)
+		if err != nil {
+			return err
+		}
+		args = append(args, arg)
+		rest = tail
+	}
+
+	want := map[byte]int{walOpSet: 2, walOpDelete: 1, walOpClear: 0, walOpMoveToFront: 1, walOpMoveToBack: 1}
+	n, known := want[op]
+	if !known {
+		return fmt.Errorf("unknown operation %d", op)
+	}
+	if len(args) != n {
+		return fmt.Errorf("operation %d has %d arguments, want %d", op, len(args), n)
+	}
+	if n > 0 && !isHashable(args[0]) {
+		return fmt.Errorf("unhashable key type %T", args[0])
+	}
+
+	switch op {
+	case walOpSet:
+		return dm.om.Set(args[0], args[1])
+	case walOpDelete:
+		return dm.om.Delete(args[0])
+	case walOpClear:
+		dm.om.Clear()
+	case walOpMoveToFront:
+		dm.om.MoveToFront(args[0])
+	case walOpMoveToBack:
+		dm.om.MoveToBack(args[0])
+	}
+	return nil
+}
+
+// compact folds the current contents into snapshot.N+1 and switches to an
+// empty wal.N+1. The caller must hold dm.mu.
+func (dm *DurableOrderedMap) compact() error {
+	next := dm.gen + 1
+
+	tmp := dm.snapshotPath(next) + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = dm.om.WriteSnapshotWithCodec(f, dm.opts.Codec)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// Create the new log before publishing the snapshot so that the snapshot
+	// becomes visible together with an empty log.
+	log, err := os.OpenFile(dm.walPath(next), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := writeWALHeader(log); err == nil {
+		err = log.Sync()
+	}
+	if err != nil {
+		log.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, dm.snapshotPath(next)); err != nil {
+		log.Close()
+		os.Remove(tmp)
+		return err
+	}
+	syncDir(dm.dir)
+
+	dm.log.Close()
+	dm.log = log
+	dm.logSize = walHeaderSize
+	dm.gen = next
+	dm.lastSync = time.Now()
+	dm.dirty = false
+	removeOldGenerations(dm.dir, next)
+	return nil
+}
+
+func (dm *DurableOrderedMap) snapshotPath(gen uint64) string {
+	return filepath.Join(dm.dir, "snapshot."+strconv.FormatUint(gen, 10))
+}
+
+func (dm *DurableOrderedMap) walPath(gen uint64) string {
+	return filepath.Join(dm.dir, "wal."+strconv.FormatUint(gen, 10))
+}
+
+func writeWALHeader(f *os.File) error {
+	var header [walHeaderSize]byte
+	copy(header[:4], walMagic)
+	binary.BigEndian.PutUint16(header[4:6], walVersion)
+	if _, err := f.WriteAt(header[:], 0); err != nil {
+		return err
+	}
+	_, err := f.Seek(walHeaderSize, io.SeekStart)
+	return err
+}
+
+// parseGeneration extracts N from "snapshot.N" or "wal.N".
+func parseGeneration(name string) (prefix string, gen uint64, ok bool) {
+	prefix, num, found := strings.Cut(name, ".")
+	if !found || (prefix != "snapshot" && prefix != "wal") {
+		return "", 0, false
+	}
+	gen, err := strconv.ParseUint(num, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return prefix, gen, true
+}
+
+// latestGeneration returns the highest generation that has a complete
+// snapshot, or zero if there is none.
+func latestGeneration(dir string) (uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	var latest uint64
+	for _, e := range entries {
+		if prefix, gen, ok := parseGeneration(e.Name()); ok && prefix == "snapshot" && gen > latest {
+			latest = gen
+		}
+	}
+	return latest, nil
+}
+
+// removeOldGenerations deletes snapshots and logs older than gen, as well as
+// leftover temporary files. Failures are ignored; stale files are retried on
+// the next open or compaction.
+func removeOldGenerations(dir string, gen uint64) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasSuffix(name, ".tmp") {
+			os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		if _, g, ok := parseGeneration(name); ok && g < gen {
+			os.Remove(filepath.Join(dir, name))
+		}
+	}
+}
+
+// syncDir flushes directory metadata so renames survive a crash.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+}
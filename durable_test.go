@@ -0,0 +1,370 @@
+package orderedmap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func openTestDurable(t *testing.T, dir string, opts *DurableOptions) *DurableOrderedMap {
+	t.Helper()
+	dm, err := OpenDurable(dir, opts)
+	if err != nil {
+		t.Fatalf("OpenDurable failed: %v", err)
+	}
+	return dm
+}
+
+func TestDurableOrderedMap_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	dm := openTestDurable(t, dir, nil)
+
+	dm.Set("a", 1)
+	dm.Set("b", int64(2))
+	dm.Set("c", "three")
+	dm.Set("a", 10)
+	dm.Delete("b")
+	dm.Set("d", []any{1, "x"})
+	dm.MoveToFront("c")
+	dm.MoveToBack("a")
+	if err := dm.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened := openTestDurable(t, dir, nil)
+	defer reopened.Close()
+
+	expected := []any{"c", "d", "a"}
+	if keys := reopened.Keys(); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+	if v, _ := reopened.Get("a"); v != 10 {
+		t.Errorf("Expected a=10, got %v", v)
+	}
+	if v, _ := reopened.Get("d"); !reflect.DeepEqual(v, []any{1, "x"}) {
+		t.Errorf("Expected d=[1 x], got %v", v)
+	}
+}
+
+func TestDurableOrderedMap_Operations(t *testing.T) {
+	dm := openTestDurable(t, t.TempDir(), nil)
+	defer dm.Close()
+
+	if err := dm.Set(nil, 1); err == nil {
+		t.Error("Expected error for nil key")
+	}
+	if err := dm.Delete(nil); err == nil {
+		t.Error("Expected error for nil key")
+	}
+	if moved, err := dm.MoveToFront("missing"); moved || err != nil {
+		t.Errorf("Expected no-op move for missing key, got %v, %v", moved, err)
+	}
+	if err := dm.Set("fn", func() {}); err == nil {
+		t.Error("Expected error for value the codec cannot encode")
+	}
+	if dm.Has("fn") {
+		t.Error("Expected failed write not to be applied")
+	}
+
+	dm.Set("x", 1)
+	dm.Set("y", 2)
+	if moved, err := dm.MoveToFront("y"); !moved || err != nil {
+		t.Errorf("Expected move to succeed, got %v, %v", moved, err)
+	}
+	if k, _, _ := dm.First(); k != "y" {
+		t.Errorf("Expected y first, got %v", k)
+	}
+	if k, _, _ := dm.Last(); k != "x" {
+		t.Errorf("Expected x last, got %v", k)
+	}
+	if dm.Len() != 2 || dm.String() != "{y: 2, x: 1}" {
+		t.Errorf("Unexpected contents %s", dm.String())
+	}
+	if err := dm.Sync(); err != nil {
+		t.Errorf("Sync failed: %v", err)
+	}
+
+	dm.Close()
+	if err := dm.Set("z", 3); err != ErrClosed {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+	if v, ok := dm.Get("x"); !ok || v != 1 {
+		t.Error("Expected reads to keep working after Close")
+	}
+}
+
+func TestDurableOrderedMap_UnhashableKey(t *testing.T) {
+	dir := t.TempDir()
+	dm := openTestDurable(t, dir, nil)
+	dm.Set("a", 1)
+	if err := dm.Set([]any{1}, 1); err == nil {
+		t.Error("Expected error for an unhashable key")
+	}
+	if err := dm.Delete([]any{1}); err == nil {
+		t.Error("Expected error for an unhashable key")
+	}
+	if moved, err := dm.MoveToBack(map[string]any{}); moved || err == nil {
+		t.Errorf("Expected error for an unhashable key, got %v, %v", moved, err)
+	}
+	dm.Close()
+
+	// Nothing was logged, so the store still opens.
+	dm = openTestDurable(t, dir, nil)
+	defer dm.Close()
+	if dm.String() != "{a: 1}" {
+		t.Errorf("Expected {a: 1}, got %s", dm.String())
+	}
+}
+
+func TestDurableOrderedMap_SyncInterval(t *testing.T) {
+	dm := openTestDurable(t, t.TempDir(), &DurableOptions{Sync: SyncInterval, SyncInterval: 5 * time.Millisecond})
+	defer dm.Close()
+
+	dm.Set("a", 1)
+	// No further writes: the background flusher must sync the record.
+	deadline := time.Now().Add(time.Second)
+	for {
+		dm.mu.Lock()
+		dirty := dm.dirty
+		dm.mu.Unlock()
+		if !dirty {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the record to be synced without further writes")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDurableOrderedMap_TornWrite(t *testing.T) {
+	dir := t.TempDir()
+	dm := openTestDurable(t, dir, nil)
+	dm.Set("a", 1)
+	dm.Set("b", 2)
+	dm.Close()
+
+	walPath := filepath.Join(dir, "wal.0")
+	info, err := os.Stat(walPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intact := info.Size()
+
+	t.Run("Partial Record", func(t *testing.T) {
+		// A record header promising more payload than was written.
+		f, _ := os.OpenFile(walPath, os.O_WRONLY|os.O_APPEND, 0)
+		f.Write([]byte{0, 0, 0, 20, 1, 2, 3, 4, walOpSet, 1})
+		f.Close()
+
+		dm := openTestDurable(t, dir, nil)
+		if keys := dm.Keys(); !reflect.DeepEqual(keys, []any{"a", "b"}) {
+			t.Errorf("Expected intact records to survive, got %v", keys)
+		}
+		if info, _ := os.Stat(walPath); info.Size() != intact {
+			t.Errorf("Expected log truncated to %d bytes, got %d", intact, info.Size())
+		}
+
+		// New writes must land after the last intact record.
+		dm.Set("c", 3)
+		dm.Close()
+		dm = openTestDurable(t, dir, nil)
+		defer dm.Close()
+		if keys := dm.Keys(); !reflect.DeepEqual(keys, []any{"a", "b", "c"}) {
+			t.Errorf("Expected [a b c], got %v", keys)
+		}
+	})
+
+	t.Run("Corrupt Checksum", func(t *testing.T) {
+		info, _ := os.Stat(walPath)
+		data, _ := os.ReadFile(walPath)
+		data[len(data)-1] ^= 0xff
+		os.WriteFile(walPath, data, 0o644)
+
+		dm := openTestDurable(t, dir, nil)
+		defer dm.Close()
+		if dm.Has("c") {
+			t.Error("Expected record with bad checksum to be discarded")
+		}
+		if newInfo, _ := os.Stat(walPath); newInfo.Size() >= info.Size() {
+			t.Error("Expected corrupt tail to be truncated")
+		}
+	})
+
+	t.Run("Corrupt Middle Record", func(t *testing.T) {
+		dir := t.TempDir()
+		dm := openTestDurable(t, dir, nil)
+		for i := 0; i < 5; i++ {
+			dm.Set(fmt.Sprint("k", i), i)
+		}
+		dm.Close()
+
+		walPath := filepath.Join(dir, "wal.0")
+		data, _ := os.ReadFile(walPath)
+		// Flip a payload byte of the first record.
+		data[walHeaderSize+walRecordHeaderSize+1] ^= 0xff
+		os.WriteFile(walPath, data, 0o644)
+
+		if _, err := OpenDurable(dir, nil); !errors.Is(err, ErrCorruptLog) {
+			t.Fatalf("Expected ErrCorruptLog, got %v", err)
+		}
+		if after, _ := os.ReadFile(walPath); !bytes.Equal(after, data) {
+			t.Errorf("Expected log to be left untouched, size %d -> %d", len(data), len(after))
+		}
+	})
+
+	t.Run("Zero Filled Tail", func(t *testing.T) {
+		dir := t.TempDir()
+		dm := openTestDurable(t, dir, nil)
+		dm.Set("a", 1)
+		dm.Close()
+
+		walPath := filepath.Join(dir, "wal.0")
+		f, _ := os.OpenFile(walPath, os.O_WRONLY|os.O_APPEND, 0)
+		f.Write(make([]byte, 64))
+		f.Close()
+
+		dm = openTestDurable(t, dir, nil)
+		defer dm.Close()
+		if v, _ := dm.Get("a"); v != 1 {
+			t.Errorf("Expected a=1, got %v", v)
+		}
+	})
+
+	t.Run("Torn Header", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "wal.0"), []byte("OW"), 0o644)
+		dm := openTestDurable(t, dir, nil)
+		dm.Set("k", "v")
+		dm.Close()
+		dm = openTestDurable(t, dir, nil)
+		defer dm.Close()
+		if v, _ := dm.Get("k"); v != "v" {
+			t.Errorf("Expected k=v, got %v", v)
+		}
+	})
+
+	t.Run("Not A Log", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "wal.0"), []byte("something else"), 0o644)
+		if _, err := OpenDurable(dir, nil); err == nil {
+			t.Error("Expected error for foreign file")
+		}
+	})
+}
+
+func TestDurableOrderedMap_Compaction(t *testing.T) {
+	dir := t.TempDir()
+	opts := &DurableOptions{CompactThreshold: 256, Sync: SyncNever}
+	dm := openTestDurable(t, dir, opts)
+
+	for i := 0; i < 100; i++ {
+		if err := dm.Set(i%10, i); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	dm.MoveToFront(9)
+	dm.Delete(5)
+	dm.Close()
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		names := make([]string, len(entries))
+		for i, e := range entries {
+			names[i] = e.Name()
+		}
+		t.Errorf("Expected one snapshot and one log after compaction, got %v", names)
+	}
+
+	dm = openTestDurable(t, dir, opts)
+	defer dm.Close()
+	expected := []any{9, 0, 1, 2, 3, 4, 6, 7, 8}
+	if keys := dm.Keys(); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+	if v, _ := dm.Get(3); v != 93 {
+		t.Errorf("Expected 3=93, got %v", v)
+	}
+}
+
+func TestDurableOrderedMap_CrashRecovery(t *testing.T) {
+	t.Run("Without Close", func(t *testing.T) {
+		dir := t.TempDir()
+		dm := openTestDurable(t, dir, &DurableOptions{Sync: SyncInterval})
+		for i := 0; i < 20; i++ {
+			dm.Set(fmt.Sprintf("k%02d", i), i)
+		}
+		dm.MoveToFront("k10")
+		dm.Clear()
+		dm.Set("after-clear", true)
+		dm.Set("k05", 5)
+		dm.MoveToFront("k05")
+
+		// Simulate a crash: open a second instance while the first one
+		// never flushed or closed its log.
+		recovered := openTestDurable(t, dir, nil)
+		defer recovered.Close()
+		if !reflect.DeepEqual(recovered.Keys(), dm.Keys()) {
+			t.Errorf("Expected recovered order %v, got %v", dm.Keys(), recovered.Keys())
+		}
+	})
+
+	t.Run("Interrupted Compaction", func(t *testing.T) {
+		dir := t.TempDir()
+		dm := openTestDurable(t, dir, nil)
+		dm.Set("a", 1)
+		dm.Set("b", 2)
+		dm.Compact()
+		dm.Set("c", 3)
+		dm.MoveToFront("c")
+		dm.Close()
+
+		// A crash before the snapshot rename leaves a temporary snapshot and
+		// an empty log for the next generation behind.
+		os.WriteFile(filepath.Join(dir, "snapshot.2.tmp"), []byte("partial"), 0o644)
+		os.WriteFile(filepath.Join(dir, "wal.2"), nil, 0o644)
+
+		dm = openTestDurable(t, dir, nil)
+		defer dm.Close()
+		if keys := dm.Keys(); !reflect.DeepEqual(keys, []any{"c", "a", "b"}) {
+			t.Errorf("Expected [c a b], got %v", keys)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "snapshot.2.tmp")); !os.IsNotExist(err) {
+			t.Error("Expected temporary snapshot to be removed")
+		}
+	})
+}
+
+func TestDurableOrderedMap_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	dm := openTestDurable(t, dir, &DurableOptions{Sync: SyncNever, CompactThreshold: 4096})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(base int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				dm.Set(base*100+i, i)
+				dm.Get(base*100 + i)
+				if i%5 == 0 {
+					dm.Delete(base*100 + i)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	dm.Close()
+
+	reopened := openTestDurable(t, dir, nil)
+	defer reopened.Close()
+	if !reflect.DeepEqual(reopened.Keys(), dm.Keys()) {
+		t.Error("Expected reopened map to match the in-memory order")
+	}
+}
@@ -0,0 +1,198 @@
+package orderedmap
+
+// Element is a handle to an entry of an OrderedMap, in the style of
+// container/list. Walking and editing the map through elements avoids
+// looking keys up again.
+//
+// An element stays valid until its entry is deleted or the map is cleared.
+// A deleted (detached) element still reports the key and last value it had,
+// but Next and Prev return nil and the element-based methods of the map
+// ignore it. Setting the same key again creates a new entry; the old element
+// does not come back to life.
+type Element struct {
+	om   *OrderedMap
+	node *Node
+}
+
+// Key returns the key of the element.
+func (e *Element) Key() any {
+	return e.node.Key
+}
+
+// Value returns the current value of the element, or its last value if it
+// has been deleted.
+func (e *Element) Value() any {
+	e.om.rlock()
+	defer e.om.runlock()
+	return e.node.Value
+}
+
+// Next returns the next element, or nil if e is the last element or has been
+// deleted.
+func (e *Element) Next() *Element {
+	e.om.rlock()
+	defer e.om.runlock()
+
+	if !e.om.owns(e) {
+		return nil
+	}
+	return e.om.element(e.node.next)
+}
+
+// Prev returns the previous element, or nil if e is the first element or has
+// been deleted.
+func (e *Element) Prev() *Element {
+	e.om.rlock()
+	defer e.om.runlock()
+
+	if !e.om.owns(e) {
+		return nil
+	}
+	return e.om.element(e.node.prev)
+}
+
+// Front returns the first element of the map, or nil if the map is empty.
+// This method is thread-safe.
+//
+// Example:
+//
+//	for e := om.Front(); e != nil; e = e.Next() {
+//	    fmt.Println(e.Key(), e.Value())
+//	}
+func (om *OrderedMap) Front() *Element {
+	om.rlock()
+	defer om.runlock()
+	return om.element(om.head)
+}
+
+// Back returns the last element of the map, or nil if the map is empty.
+// This method is thread-safe.
+//
+// Example:
+//
+//	for e := om.Back(); e != nil; e = e.Prev() {
+//	    fmt.Println(e.Key(), e.Value())
+//	}
+func (om *OrderedMap) Back() *Element {
+	om.rlock()
+	defer om.runlock()
+	return om.element(om.tail)
+}
+
+// GetElement returns the element for key, or nil if the key is nil or
+// doesn't exist. This method is thread-safe.
+//
+// Example:
+//
+//	if e := om.GetElement("key"); e != nil {
+//	    om.UpdateValue(e, e.Value().(int)+1)
+//	}
+func (om *OrderedMap) GetElement(key any) *Element {
+	om.rlock()
+	defer om.runlock()
+
+	node, exists := om.lookup(key)
+	if !exists {
+		return nil
+	}
+	return om.element(node)
+}
+
+// Remove deletes the entry of e from the map. Returns false if e is nil,
+// belongs to another map or has already been deleted.
+// This method is thread-safe.
+//
+// Example:
+//
+//	for e := om.Front(); e != nil; {
+//	    next := e.Next()
+//	    if e.Value() == nil {
+//	        om.Remove(e)
+//	    }
+//	    e = next
+//	}
+func (om *OrderedMap) Remove(e *Element) bool {
+	om.lock()
+	defer om.unlock()
+
+	if !om.owns(e) {
+		return false
+	}
+	om.remove(e.node)
+	return true
+}
+
+// MoveBefore moves e directly before mark. Returns false if either element
+// is nil, belongs to another map or has been deleted. Moving an element
+// before itself is a no-op that returns true.
+// This method is thread-safe.
+//
+// Example:
+//
+//	om.MoveBefore(om.GetElement("b"), om.GetElement("a"))
+func (om *OrderedMap) MoveBefore(e, mark *Element) bool {
+	om.lock()
+	defer om.unlock()
+
+	if !om.owns(e) || !om.owns(mark) {
+		return false
+	}
+	if e.node != mark.node {
+		om.moveAfter(e.node, mark.node.prev)
+	}
+	return true
+}
+
+// MoveAfter moves e directly after mark. Returns false if either element is
+// nil, belongs to another map or has been deleted. Moving an element after
+// itself is a no-op that returns true.
+// This method is thread-safe.
+//
+// Example:
+//
+//	om.MoveAfter(om.GetElement("a"), om.GetElement("b"))
+func (om *OrderedMap) MoveAfter(e, mark *Element) bool {
+	om.lock()
+	defer om.unlock()
+
+	if !om.owns(e) || !om.owns(mark) {
+		return false
+	}
+	om.moveAfter(e.node, mark.node)
+	return true
+}
+
+// UpdateValue replaces the value of e without looking up its key. Returns
+// false if e is nil, belongs to another map or has been deleted.
+// This method is thread-safe.
+//
+// Example:
+//
+//	e := om.GetElement("counter")
+//	om.UpdateValue(e, e.Value().(int)+1)
+func (om *OrderedMap) UpdateValue(e *Element, value any) bool {
+	om.lock()
+	defer om.unlock()
+
+	if !om.owns(e) {
+		return false
+	}
+	om.prepareWrite()
+	old := e.node.Value
+	e.node.Value = value
+	om.emit(Event{Type: EventUpdated, Key: e.node.Key, Value: value, OldValue: old})
+	return true
+}
+
+// element wraps node in an Element, or returns nil for a nil node.
+func (om *OrderedMap) element(node *Node) *Element {
+	if node == nil {
+		return nil
+	}
+	return &Element{om: om, node: node}
+}
+
+// owns reports whether e is a live element of om. The caller must hold om.mu.
+func (om *OrderedMap) owns(e *Element) bool {
+	return e != nil && e.om == om && om.alive(e.node)
+}
@@ -0,0 +1,144 @@
+package orderedmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrderedMap_Elements(t *testing.T) {
+	newMap := func() *OrderedMap {
+		om := NewOrderedMap()
+		for _, k := range []string{"a", "b", "c", "d"} {
+			om.Set(k, k)
+		}
+		return om
+	}
+
+	t.Run("Walk", func(t *testing.T) {
+		om := newMap()
+		var forward, backward []any
+		for e := om.Front(); e != nil; e = e.Next() {
+			forward = append(forward, e.Key())
+		}
+		for e := om.Back(); e != nil; e = e.Prev() {
+			backward = append(backward, e.Value())
+		}
+		if !reflect.DeepEqual(forward, []any{"a", "b", "c", "d"}) {
+			t.Errorf("Unexpected forward walk %v", forward)
+		}
+		if !reflect.DeepEqual(backward, []any{"d", "c", "b", "a"}) {
+			t.Errorf("Unexpected backward walk %v", backward)
+		}
+
+		empty := NewOrderedMap()
+		if empty.Front() != nil || empty.Back() != nil {
+			t.Error("Expected nil elements for an empty map")
+		}
+		if om.GetElement("missing") != nil || om.GetElement(nil) != nil {
+			t.Error("Expected nil element for a missing key")
+		}
+	})
+
+	t.Run("UpdateValue", func(t *testing.T) {
+		om := newMap()
+		var events []Event
+		om.OnChange(func(ev Event) { events = append(events, ev) })
+
+		e := om.GetElement("b")
+		if !om.UpdateValue(e, 42) {
+			t.Fatal("Expected UpdateValue to succeed")
+		}
+		if v, _ := om.Get("b"); v != 42 || e.Value() != 42 {
+			t.Errorf("Expected b=42, got %v", v)
+		}
+		if len(events) != 1 || events[0].Type != EventUpdated || events[0].OldValue != "b" {
+			t.Errorf("Unexpected events %v", events)
+		}
+	})
+
+	t.Run("Move", func(t *testing.T) {
+		om := newMap()
+		a, c, d := om.GetElement("a"), om.GetElement("c"), om.GetElement("d")
+
+		if !om.MoveBefore(d, a) || om.String() != "{d: d, a: a, b: b, c: c}" {
+			t.Errorf("Unexpected state after MoveBefore: %s", om.String())
+		}
+		if !om.MoveAfter(a, c) || om.String() != "{d: d, b: b, c: c, a: a}" {
+			t.Errorf("Unexpected state after MoveAfter: %s", om.String())
+		}
+		if !om.MoveBefore(c, c) || !om.MoveAfter(c, c) || om.String() != "{d: d, b: b, c: c, a: a}" {
+			t.Errorf("Expected moving relative to itself to be a no-op: %s", om.String())
+		}
+		if !om.MoveBefore(om.GetElement("b"), c) || om.String() != "{d: d, b: b, c: c, a: a}" {
+			t.Errorf("Expected moving to the current position to be a no-op: %s", om.String())
+		}
+		if k, _, _ := om.Last(); k != "a" {
+			t.Errorf("Expected tail a, got %v", k)
+		}
+	})
+
+	t.Run("Detached", func(t *testing.T) {
+		om := newMap()
+		b := om.GetElement("b")
+		c := om.GetElement("c")
+
+		if !om.Remove(b) {
+			t.Fatal("Expected Remove to succeed")
+		}
+		if om.Remove(b) {
+			t.Error("Expected second Remove to fail")
+		}
+		if b.Next() != nil || b.Prev() != nil {
+			t.Error("Expected detached element to have no neighbors")
+		}
+		if b.Key() != "b" || b.Value() != "b" {
+			t.Error("Expected detached element to keep its key and value")
+		}
+		if om.UpdateValue(b, 1) || om.MoveBefore(b, c) || om.MoveAfter(c, b) {
+			t.Error("Expected operations on a detached element to fail")
+		}
+
+		// Re-adding the key creates a new element.
+		om.Set("b", "new")
+		if om.UpdateValue(b, 1) || b.Next() != nil {
+			t.Error("Expected the old element to stay detached")
+		}
+		if e := om.GetElement("b"); e == nil || e.Value() != "new" {
+			t.Error("Expected a new element for the re-added key")
+		}
+		if c.Prev().Key() != "a" {
+			t.Errorf("Expected a before c, got %v", c.Prev().Key())
+		}
+
+		om.Clear()
+		if c.Next() != nil || om.Remove(c) {
+			t.Error("Expected elements to be detached by Clear")
+		}
+		if om.Remove(nil) || om.MoveBefore(nil, nil) {
+			t.Error("Expected nil elements to be rejected")
+		}
+	})
+
+	t.Run("Foreign Element", func(t *testing.T) {
+		om1, om2 := newMap(), newMap()
+		if om2.Remove(om1.Front()) || om2.MoveAfter(om2.Front(), om1.Back()) {
+			t.Error("Expected elements of another map to be rejected")
+		}
+		if om1.Len() != 4 || om2.Len() != 4 {
+			t.Error("Expected both maps to be unchanged")
+		}
+	})
+
+	t.Run("Snapshot Isolation", func(t *testing.T) {
+		om := newMap()
+		snap := om.Snapshot()
+		om.UpdateValue(om.Front(), "changed")
+		om.MoveAfter(om.Front(), om.Back())
+		if snap.String() != "{a: a, b: b, c: c, d: d}" {
+			t.Errorf("Snapshot changed: %s", snap.String())
+		}
+		if om.String() != "{b: b, c: c, d: d, a: changed}" {
+			t.Errorf("Unexpected state %s", om.String())
+		}
+	})
+}
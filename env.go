@@ -0,0 +1,224 @@
+package orderedmap
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseEnv reads a .env file into a new OrderedMap of string values, in file
+// order. Each line is blank, a comment starting with "#", or an assignment
+// KEY=value, optionally preceded by "export". Values may be:
+//
+//   - unquoted: surrounding whitespace is trimmed, and a "#" preceded by
+//     whitespace starts a comment,
+//   - single-quoted: taken literally, possibly across lines,
+//   - double-quoted: \n, \r, \t, \", \\ and \$ are unescaped, possibly
+//     across lines.
+//
+// Variables are not expanded. If a key appears more than once, the last
+// value wins and the key keeps its first position.
+//
+// The returned map remembers the comments, blank lines and original text of
+// the file, so WriteEnv reproduces it except where entries were changed.
+//
+// Example:
+//
+//	f, err := os.Open(".env")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer f.Close()
+//	env, err := ParseEnv(f)
+func ParseEnv(r io.Reader) (*OrderedMap, error) {
+	lines, err := splitLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	om := NewOrderedMap()
+	layout := newTextLayout("env")
+	var pending []string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == '#' {
+			pending = append(pending, line)
+			continue
+		}
+
+		start := i
+		e, key, err := parseEnvEntry(lines, &i)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", start+1, err)
+		}
+		e.before = pending
+		pending = nil
+		layout.add(key, e)
+		_ = om.set(key, e.value)
+	}
+	layout.trailer = pending
+	om.layout = layout
+	return om, nil
+}
+
+// parseEnvEntry parses the assignment starting at lines[*i] and advances *i
+// to its last line.
+func parseEnvEntry(lines []string, i *int) (*layoutEntry, string, error) {
+	line := lines[*i]
+	rest := strings.TrimLeft(line, " \t")
+	if after, ok := strings.CutPrefix(rest, "export"); ok && strings.HasPrefix(after, " ") {
+		rest = strings.TrimLeft(after, " \t")
+	}
+	eq := strings.IndexByte(rest, '=')
+	if eq < 0 {
+		return nil, "", fmt.Errorf("missing '=' in %q", line)
+	}
+	key := strings.TrimSpace(rest[:eq])
+	if !isEnvName(key) {
+		return nil, "", fmt.Errorf("invalid variable name %q", key)
+	}
+	text := strings.TrimLeft(rest[eq+1:], " \t")
+	e := &layoutEntry{head: line[:len(line)-len(text)], raw: []string{line}}
+
+	if strings.HasPrefix(text, "#") && len(text) < len(rest[eq+1:]) {
+		// Whitespace after "=" followed by "#": an empty value and a comment.
+		// The whitespace goes with the comment so a new value keeps it.
+		e.head = line[:len(line)-len(rest[eq+1:])]
+		e.value, e.comment = "", rest[eq+1:]
+		return e, key, nil
+	}
+	if text == "" || (text[0] != '"' && text[0] != '\'') {
+		value := text
+		for j := 1; j < len(text); j++ {
+			if text[j] == '#' && (text[j-1] == ' ' || text[j-1] == '\t') {
+				value = text[:j]
+				break
+			}
+		}
+		value = strings.TrimRight(value, " \t")
+		e.value, e.comment = value, text[len(value):]
+		return e, key, nil
+	}
+
+	quote := text[0]
+	var sb strings.Builder
+	j := 1
+	for {
+		if j >= len(text) {
+			if *i+1 >= len(lines) {
+				return nil, "", fmt.Errorf("unterminated quoted value for %s", key)
+			}
+			// The value continues on the next line.
+			*i++
+			text = lines[*i]
+			e.raw = append(e.raw, text)
+			sb.WriteByte('\n')
+			j = 0
+			continue
+		}
+		c := text[j]
+		if c == quote {
+			j++
+			break
+		}
+		if c == '\\' && quote == '"' && j+1 < len(text) {
+			j++
+			switch text[j] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '"', '\\', '$':
+				sb.WriteByte(text[j])
+			default:
+				sb.WriteByte('\\')
+				sb.WriteByte(text[j])
+			}
+			j++
+			continue
+		}
+		sb.WriteByte(c)
+		j++
+	}
+
+	tail := text[j:]
+	if t := strings.TrimSpace(tail); t != "" && t[0] != '#' {
+		return nil, "", fmt.Errorf("unexpected %q after quoted value for %s", t, key)
+	}
+	e.value, e.comment = sb.String(), tail
+	return e, key, nil
+}
+
+// isEnvName reports whether s is a valid variable name: letters, digits,
+// "_", "." and "-", not starting with a digit.
+func isEnvName(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteEnv writes the map to w in .env format, one KEY=value line per entry
+// in map order. Values are formatted with fmt.Sprint and double-quoted when
+// they contain whitespace, quotes, "#", "$" or backslashes.
+//
+// If the map was returned by ParseEnv, its comments and blank lines are
+// written back in place, and unchanged entries keep their original text, so
+// changing one value changes one line. Deleted keys are left out along with
+// the comments above them, and new keys are added at their position in the
+// map. An error is returned if a key is not a valid variable name.
+// This method is thread-safe.
+//
+// Example:
+//
+//	env.Set("PORT", "8080")
+//	if err := env.WriteEnv(f); err != nil {
+//	    log.Fatal(err)
+//	}
+func (om *OrderedMap) WriteEnv(w io.Writer) error {
+	return om.writeText(w, "env", func(e *layoutEntry, key, value any) (string, error) {
+		name := fmt.Sprint(key)
+		if !isEnvName(name) {
+			return "", fmt.Errorf("invalid variable name %q", name)
+		}
+		if e == nil {
+			return name + "=" + quoteEnvValue(textValue(value)), nil
+		}
+		return e.head + quoteEnvValue(textValue(value)) + e.comment, nil
+	})
+}
+
+// quoteEnvValue returns s as it is written after the "=" of a .env line.
+func quoteEnvValue(s string) string {
+	if !strings.ContainsAny(s, " \t\r\n#\"'\\$`") {
+		return s
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '"', '\\', '$':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,213 @@
+package orderedmap
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseEnv(t *testing.T) {
+	t.Run("Values", func(t *testing.T) {
+		input := "# database\n" +
+			"DB_HOST=localhost\n" +
+			"export DB_PORT = 5432 # default port\n" +
+			"EMPTY=\n" +
+			"URL=http://example.com/#frag\n" +
+			"SINGLE='raw \\n $HOME'\n" +
+			"DOUBLE=\"line1\\nline2 \\\"q\\\" \\$HOME\"\n" +
+			"MULTI=\"first\n" +
+			"second\"\n"
+		env, err := ParseEnv(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []Pair{
+			{"DB_HOST", "localhost"},
+			{"DB_PORT", "5432"},
+			{"EMPTY", ""},
+			{"URL", "http://example.com/#frag"},
+			{"SINGLE", `raw \n $HOME`},
+			{"DOUBLE", "line1\nline2 \"q\" $HOME"},
+			{"MULTI", "first\nsecond"},
+		}
+		if env.Len() != len(expected) {
+			t.Fatalf("Expected %d entries, got %d", len(expected), env.Len())
+		}
+		for i, key := range env.Keys() {
+			value, _ := env.Get(key)
+			if key != expected[i].Key || value != expected[i].Value {
+				t.Errorf("Expected %v=%q, got %v=%q", expected[i].Key, expected[i].Value, key, value)
+			}
+		}
+	})
+
+	t.Run("Duplicate Keys", func(t *testing.T) {
+		env, err := ParseEnv(strings.NewReader("A=1\nB=2\nA=3\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if env.String() != "{A: 3, B: 2}" {
+			t.Errorf("Expected {A: 3, B: 2}, got %s", env.String())
+		}
+	})
+
+	t.Run("Comment After Separator", func(t *testing.T) {
+		env, err := ParseEnv(strings.NewReader("A= # note\nB=\t# note\nC=#value\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if env.String() != "{A: , B: , C: #value}" {
+			t.Errorf("Expected {A: , B: , C: #value}, got %s", env.String())
+		}
+
+		env.Set("A", "x")
+		var buf bytes.Buffer
+		if err := env.WriteEnv(&buf); err != nil {
+			t.Fatal(err)
+		}
+		expected := "A=x # note\nB=\t# note\nC=#value\n"
+		if buf.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, input := range []string{
+			"NOEQUALS\n",
+			"1BAD=x\n",
+			"A=\"unterminated\n",
+			"A=\"x\" trailing\n",
+		} {
+			if _, err := ParseEnv(strings.NewReader(input)); err == nil {
+				t.Errorf("Expected error for %q", input)
+			}
+		}
+	})
+}
+
+func TestOrderedMap_WriteEnv(t *testing.T) {
+	input := "# Service settings\n" +
+		"\n" +
+		"export NAME='api'   # service name\n" +
+		"PORT=8080\n" +
+		"  # TLS\n" +
+		"CERT=\"-----BEGIN\n" +
+		"-----END\"\n" +
+		"OLD=remove me\n" +
+		"\n" +
+		"# end\n"
+
+	t.Run("Round Trip", func(t *testing.T) {
+		env, err := ParseEnv(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := env.WriteEnv(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != input {
+			t.Errorf("Expected unchanged output, got:\n%s", buf.String())
+		}
+	})
+
+	t.Run("Minimal Diff", func(t *testing.T) {
+		env, _ := ParseEnv(strings.NewReader(input))
+		env.Set("NAME", "my api")
+		env.Set("CERT", "new")
+		env.Delete("OLD")
+		env.Set("DEBUG", true)
+
+		var buf bytes.Buffer
+		if err := env.WriteEnv(&buf); err != nil {
+			t.Fatal(err)
+		}
+		expected := "# Service settings\n" +
+			"\n" +
+			"export NAME=\"my api\"   # service name\n" +
+			"PORT=8080\n" +
+			"  # TLS\n" +
+			"CERT=new\n" +
+			"DEBUG=true\n" +
+			"\n" +
+			"# end\n"
+		if buf.String() != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+		}
+	})
+
+	t.Run("Replaced Contents", func(t *testing.T) {
+		env, _ := ParseEnv(strings.NewReader(input))
+		env.Clear()
+		env.Set("PORT", 1)
+		var buf bytes.Buffer
+		if err := env.WriteEnv(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != "PORT=1\n" {
+			t.Errorf("Expected %q after Clear, got %q", "PORT=1\n", buf.String())
+		}
+
+		env, _ = ParseEnv(strings.NewReader(input))
+		if err := env.UnmarshalJSON([]byte(`{"PORT": "1"}`)); err != nil {
+			t.Fatal(err)
+		}
+		buf.Reset()
+		if err := env.WriteEnv(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != "PORT=1\n" {
+			t.Errorf("Expected %q after UnmarshalJSON, got %q", "PORT=1\n", buf.String())
+		}
+
+		env, _ = ParseEnv(strings.NewReader(input))
+		err := env.Update(func(tx *Tx) error {
+			tx.Clear()
+			return errors.New("rollback")
+		})
+		if err == nil {
+			t.Fatal("Expected the transaction to fail")
+		}
+		buf.Reset()
+		if err := env.WriteEnv(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != input {
+			t.Errorf("Expected the layout back after a rollback, got:\n%s", buf.String())
+		}
+	})
+
+	t.Run("Quoting", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set("PLAIN", "abc")
+		om.Set("SPACES", "a b")
+		om.Set("SPECIAL", "x\"y\\z$w\n")
+		om.Set("NIL", nil)
+
+		var buf bytes.Buffer
+		if err := om.WriteEnv(&buf); err != nil {
+			t.Fatal(err)
+		}
+		expected := "PLAIN=abc\nSPACES=\"a b\"\nSPECIAL=\"x\\\"y\\\\z\\$w\\n\"\nNIL=\n"
+		if buf.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, buf.String())
+		}
+
+		parsed, err := ParseEnv(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v, _ := parsed.Get("SPECIAL"); v != "x\"y\\z$w\n" {
+			t.Errorf("Expected value to round trip, got %q", v)
+		}
+	})
+
+	t.Run("Invalid Name", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set("NOT VALID", "x")
+		if err := om.WriteEnv(&bytes.Buffer{}); err == nil {
+			t.Error("Expected error for an invalid name")
+		}
+	})
+}
@@ -0,0 +1,411 @@
+package orderedmap
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// EventType identifies the kind of change described by an Event.
+type EventType int
+
+const (
+	// EventInserted reports a new key added to the map.
+	EventInserted EventType = iota + 1
+	// EventUpdated reports a new value stored under an existing key.
+	EventUpdated
+	// EventDeleted reports a key removed from the map.
+	EventDeleted
+	// EventMoved reports an existing key that changed position.
+	EventMoved
+	// EventCleared reports that all keys were removed at once.
+	EventCleared
+)
+
+// String returns the name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventInserted:
+		return "Inserted"
+	case EventUpdated:
+		return "Updated"
+	case EventDeleted:
+		return "Deleted"
+	case EventMoved:
+		return "Moved"
+	case EventCleared:
+		return "Cleared"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event describes a single committed change to an OrderedMap.
+type Event struct {
+	Type EventType
+	// Key is the affected key. It is nil for EventCleared.
+	Key any
+	// Value is the new value for EventInserted and EventUpdated, the removed
+	// value for EventDeleted and the current value for EventMoved.
+	Value any
+	// OldValue is the value replaced by an EventUpdated.
+	OldValue any
+	// After is the key directly before Key once the change is applied, for
+	// EventInserted and EventMoved. It is nil if Key is now the first key.
+	After any
+}
+
+// OverflowPolicy decides what happens when a subscriber's channel is full.
+type OverflowPolicy int
+
+const (
+	// DropNewest discards events that don't fit in the channel buffer.
+	// Writers never wait for the subscriber.
+	DropNewest OverflowPolicy = iota
+	// Block delivers every event in commit order. Events are queued and
+	// handed to the channel by a separate goroutine; once more than Buffer
+	// events are queued, a writer waits after releasing the map's lock until
+	// the subscriber catches up or its context is canceled. Writers are thus
+	// slowed to the pace of the subscriber, which may still read the map.
+	// A subscriber that writes to the map itself while its queue is full
+	// waits for its own queue and never returns.
+	Block
+	// Coalesce queues events without bound but folds an EventUpdated into
+	// the pending event of the same key, so a slow subscriber sees the latest
+	// value of each key rather than every intermediate one. The merged event
+	// keeps its place in the queue, so inserts, moves and deletes still
+	// arrive in commit order and After always names a key the subscriber
+	// knows about. Writers never wait for the subscriber.
+	Coalesce
+)
+
+// SubscribeOptions represents configuration options for SubscribeWithOptions.
+type SubscribeOptions struct {
+	// Buffer is the capacity of the returned channel. Defaults to 64.
+	Buffer int
+	// Policy selects the behavior when the channel is full. Defaults to DropNewest.
+	Policy OverflowPolicy
+}
+
+// defaultSubscribeBuffer is the channel capacity used when none is given.
+const defaultSubscribeBuffer = 64
+
+// Subscribe returns a channel that receives an Event for every change to the
+// map until ctx is canceled, after which the channel is closed. Events are
+// produced while the write lock is held, so they arrive in commit order.
+// The channel is buffered and events that don't fit are dropped; use
+// SubscribeWithOptions to choose a different policy.
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	defer cancel()
+//	for ev := range om.Subscribe(ctx) {
+//	    fmt.Printf("%v %v\n", ev.Type, ev.Key)
+//	}
+func (om *OrderedMap) Subscribe(ctx context.Context) <-chan Event {
+	return om.SubscribeWithOptions(ctx, nil)
+}
+
+// SubscribeWithOptions is like Subscribe but lets the caller choose the buffer
+// size and the policy applied to slow subscribers.
+func (om *OrderedMap) SubscribeWithOptions(ctx context.Context, opts *SubscribeOptions) <-chan Event {
+	if opts == nil {
+		opts = &SubscribeOptions{}
+	}
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = defaultSubscribeBuffer
+	}
+
+	sub := &subscriber{
+		ctx:    ctx,
+		policy: opts.Policy,
+		ch:     make(chan Event, buffer),
+		limit:  buffer,
+	}
+	if sub.queued() {
+		sub.signal = make(chan struct{}, 1)
+	}
+	if sub.policy == Coalesce {
+		sub.index = make(map[any]int)
+	}
+
+	om.lock()
+	if ctx.Err() != nil {
+		om.unlock()
+		close(sub.ch)
+		return sub.ch
+	}
+	obs := om.observersLocked()
+	obs.subs = append(obs.subs, sub)
+	om.unlock()
+
+	if sub.queued() {
+		go sub.forward()
+	}
+
+	context.AfterFunc(ctx, func() {
+		om.lock()
+		om.observers.removeSubscriber(sub)
+		om.unlock()
+		if !sub.queued() {
+			close(sub.ch)
+		}
+	})
+	return sub.ch
+}
+
+// OnChange registers fn to be called synchronously for every change to the
+// map. The returned function unregisters the hook.
+//
+// Hooks run while the map's write lock is held, immediately after the change
+// is applied, so they observe changes in commit order. A hook must not call
+// methods of the same map and should return quickly.
+//
+// Example:
+//
+//	unregister := om.OnChange(func(ev Event) {
+//	    log.Printf("%v %v", ev.Type, ev.Key)
+//	})
+//	defer unregister()
+func (om *OrderedMap) OnChange(fn func(Event)) (unregister func()) {
+	return om.addHook(fn)
+}
+
+// OnSet registers fn to be called synchronously whenever a key is inserted or
+// its value is updated. See OnChange for the calling rules.
+func (om *OrderedMap) OnSet(fn func(Event)) (unregister func()) {
+	return om.addHook(func(ev Event) {
+		if ev.Type == EventInserted || ev.Type == EventUpdated {
+			fn(ev)
+		}
+	})
+}
+
+// OnDelete registers fn to be called synchronously whenever a key is deleted.
+// Clear reports a single EventCleared through OnChange instead of one
+// deletion per key. See OnChange for the calling rules.
+func (om *OrderedMap) OnDelete(fn func(Event)) (unregister func()) {
+	return om.addHook(func(ev Event) {
+		if ev.Type == EventDeleted {
+			fn(ev)
+		}
+	})
+}
+
+func (om *OrderedMap) addHook(fn func(Event)) func() {
+	om.lock()
+	defer om.unlock()
+
+	obs := om.observersLocked()
+	obs.nextID++
+	id := obs.nextID
+	obs.hooks = append(obs.hooks, hook{id: id, fn: fn})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			om.lock()
+			defer om.unlock()
+			om.observers.removeHook(id)
+		})
+	}
+}
+
+// observersLocked returns the observer registry, creating it if needed.
+// The caller must hold om.mu for writing.
+func (om *OrderedMap) observersLocked() *observers {
+	if om.observers == nil {
+		om.observers = &observers{}
+	}
+	return om.observers
+}
+
+// emit wakes waiters and delivers ev to all hooks and subscribers. Inside a
+// transaction the event is held back until commit. The caller must hold om.mu
+// for writing.
+func (om *OrderedMap) emit(ev Event) {
+	if om.tx != nil {
+		// Nobody can start waiting or subscribe while the transaction holds
+		// the lock, so events only need to be kept if someone listens already.
+		if om.observers != nil || om.waitc != nil {
+			om.tx.events = append(om.tx.events, ev)
+		}
+		return
+	}
+	om.wake()
+	if om.observers == nil {
+		return
+	}
+	for _, h := range om.observers.hooks {
+		h.fn(ev)
+	}
+	for _, sub := range om.observers.subs {
+		if sub.deliver(ev) && !slices.Contains(om.stalled, sub) {
+			om.stalled = append(om.stalled, sub)
+		}
+	}
+}
+
+// waitStalled waits until the queues of the subscribers in stalled have room
+// again. It is called by unlock after the lock is released, so subscribers
+// can read the map meanwhile.
+func waitStalled(stalled []*subscriber) {
+	for _, sub := range stalled {
+		sub.waitRoom()
+	}
+}
+
+// observers holds the hooks and subscribers of a map. It is guarded by the
+// owning map's mutex.
+type observers struct {
+	nextID int
+	hooks  []hook
+	subs   []*subscriber
+}
+
+type hook struct {
+	id int
+	fn func(Event)
+}
+
+func (o *observers) removeHook(id int) {
+	for i, h := range o.hooks {
+		if h.id == id {
+			o.hooks = append(o.hooks[:i:i], o.hooks[i+1:]...)
+			return
+		}
+	}
+}
+
+func (o *observers) removeSubscriber(sub *subscriber) {
+	for i, s := range o.subs {
+		if s == sub {
+			o.subs = append(o.subs[:i:i], o.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// subscriber is a channel registered through Subscribe.
+type subscriber struct {
+	ctx    context.Context
+	policy OverflowPolicy
+	ch     chan Event
+	limit  int // Queued events above which Block writers wait
+
+	// Queue state for Block and Coalesce. pending holds queued events in
+	// order; index maps a key to the position of its latest pending event.
+	// roomc is closed when the forwarder takes the queue.
+	mu      sync.Mutex
+	pending []Event
+	index   map[any]int
+	signal  chan struct{}
+	roomc   chan struct{}
+}
+
+// queued reports whether events go through the pending queue and the
+// forwarding goroutine rather than straight to the channel.
+func (s *subscriber) queued() bool {
+	return s.policy == Block || s.policy == Coalesce
+}
+
+// deliver hands ev to the subscriber without waiting. It reports whether the
+// queue is over its limit, in which case the writer must call waitRoom after
+// releasing the map's lock.
+func (s *subscriber) deliver(ev Event) bool {
+	if !s.queued() {
+		select {
+		case s.ch <- ev:
+		default:
+		}
+		return false
+	}
+
+	s.mu.Lock()
+	if s.policy == Coalesce {
+		s.coalesce(ev)
+	} else {
+		s.pending = append(s.pending, ev)
+	}
+	full := s.policy == Block && len(s.pending) > s.limit
+	s.mu.Unlock()
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+	return full
+}
+
+// waitRoom waits until at most limit events are queued or the context is
+// done.
+func (s *subscriber) waitRoom() {
+	for {
+		s.mu.Lock()
+		if len(s.pending) <= s.limit || s.ctx.Err() != nil {
+			s.mu.Unlock()
+			return
+		}
+		if s.roomc == nil {
+			s.roomc = make(chan struct{})
+		}
+		roomc := s.roomc
+		s.mu.Unlock()
+
+		select {
+		case <-roomc:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// coalesce merges ev into the pending queue. The caller must hold s.mu.
+func (s *subscriber) coalesce(ev Event) {
+	if ev.Type == EventCleared {
+		s.pending = append(s.pending[:0], ev)
+		clear(s.index)
+		return
+	}
+
+	if i, ok := s.index[ev.Key]; ok && ev.Type == EventUpdated {
+		// The pending event of the key carries the new value instead. An
+		// EventInserted or EventMoved already holds the current value, and
+		// an EventUpdated keeps its original OldValue.
+		s.pending[i].Value = ev.Value
+		return
+	}
+	s.index[ev.Key] = len(s.pending)
+	s.pending = append(s.pending, ev)
+}
+
+// forward moves queued events to the channel until the context is done.
+func (s *subscriber) forward() {
+	defer close(s.ch)
+	for {
+		select {
+		case <-s.signal:
+		case <-s.ctx.Done():
+			return
+		}
+
+		s.mu.Lock()
+		batch := s.pending
+		s.pending = nil
+		clear(s.index)
+		if s.roomc != nil {
+			close(s.roomc)
+			s.roomc = nil
+		}
+		s.mu.Unlock()
+
+		for _, ev := range batch {
+			select {
+			case s.ch <- ev:
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}
+}
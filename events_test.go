@@ -0,0 +1,320 @@
+package orderedmap
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOrderedMap_OnChange(t *testing.T) {
+	om := NewOrderedMap()
+	var events []Event
+	unregister := om.OnChange(func(ev Event) {
+		events = append(events, ev)
+	})
+
+	om.Set("a", 1)
+	om.Set("b", 2)
+	om.Set("a", 10)
+	om.MoveToFront("b")
+	om.MoveToFront("b") // already first, no event
+	om.Delete("a")
+	om.Delete("missing") // no event
+	om.Clear()
+
+	expected := []Event{
+		{Type: EventInserted, Key: "a", Value: 1},
+		{Type: EventInserted, Key: "b", Value: 2, After: "a"},
+		{Type: EventUpdated, Key: "a", Value: 10, OldValue: 1},
+		{Type: EventMoved, Key: "b", Value: 2},
+		{Type: EventDeleted, Key: "a", Value: 10},
+		{Type: EventCleared},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+
+	unregister()
+	unregister() // idempotent
+	om.Set("c", 3)
+	if len(events) != len(expected) {
+		t.Error("Expected no events after unregistering")
+	}
+}
+
+func TestOrderedMap_OnSetOnDelete(t *testing.T) {
+	om := NewOrderedMap()
+	var sets, deletes []any
+	om.OnSet(func(ev Event) { sets = append(sets, ev.Key) })
+	om.OnDelete(func(ev Event) { deletes = append(deletes, ev.Key) })
+
+	om.Set("a", 1)
+	om.Set("a", 2)
+	om.Set("b", 1)
+	om.MoveToBack("a")
+	om.Delete("b")
+	om.Clear()
+
+	if !reflect.DeepEqual(sets, []any{"a", "a", "b"}) {
+		t.Errorf("Unexpected OnSet keys %v", sets)
+	}
+	if !reflect.DeepEqual(deletes, []any{"b"}) {
+		t.Errorf("Unexpected OnDelete keys %v", deletes)
+	}
+}
+
+func TestOrderedMap_EventsFromLoaders(t *testing.T) {
+	om := NewOrderedMap()
+	om.Set("old", 1)
+	var types []EventType
+	om.OnChange(func(ev Event) { types = append(types, ev.Type) })
+
+	if err := om.UnmarshalJSON([]byte(`{"x": 1, "y": 2}`)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []EventType{EventCleared, EventInserted, EventInserted}
+	if !reflect.DeepEqual(types, expected) {
+		t.Errorf("Expected %v, got %v", expected, types)
+	}
+}
+
+func TestOrderedMap_Subscribe(t *testing.T) {
+	t.Run("Delivery And Close", func(t *testing.T) {
+		om := NewOrderedMap()
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := om.Subscribe(ctx)
+
+		om.Set("a", 1)
+		om.Delete("a")
+
+		if ev := <-ch; ev.Type != EventInserted || ev.Key != "a" {
+			t.Errorf("Expected Inserted a, got %v", ev)
+		}
+		if ev := <-ch; ev.Type != EventDeleted || ev.Key != "a" {
+			t.Errorf("Expected Deleted a, got %v", ev)
+		}
+
+		cancel()
+		select {
+		case _, ok := <-ch:
+			if ok {
+				t.Error("Expected channel to be closed")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Channel not closed after cancel")
+		}
+		om.Set("b", 2) // must not panic on the closed channel
+	})
+
+	t.Run("Canceled Context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, ok := <-NewOrderedMap().Subscribe(ctx); ok {
+			t.Error("Expected closed channel for canceled context")
+		}
+	})
+
+	t.Run("Drop Policy", func(t *testing.T) {
+		om := NewOrderedMap()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := om.SubscribeWithOptions(ctx, &SubscribeOptions{Buffer: 2})
+		for i := 0; i < 10; i++ {
+			om.Set(i, i) // must not block
+		}
+		if len(ch) != 2 {
+			t.Errorf("Expected 2 buffered events, got %d", len(ch))
+		}
+		if ev := <-ch; ev.Key != 0 {
+			t.Errorf("Expected oldest event to be kept, got %v", ev)
+		}
+	})
+
+	t.Run("Block Policy", func(t *testing.T) {
+		om := NewOrderedMap()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := om.SubscribeWithOptions(ctx, &SubscribeOptions{Buffer: 1, Policy: Block})
+
+		const n = 100
+		go func() {
+			for i := 0; i < n; i++ {
+				om.Set(i, i)
+			}
+		}()
+		for i := 0; i < n; i++ {
+			if ev := <-ch; ev.Key != i {
+				t.Fatalf("Expected event for %d, got %v", i, ev)
+			}
+		}
+	})
+
+	t.Run("Block Policy Cancel", func(t *testing.T) {
+		om := NewOrderedMap()
+		ctx, cancel := context.WithCancel(context.Background())
+		om.SubscribeWithOptions(ctx, &SubscribeOptions{Buffer: 1, Policy: Block})
+
+		done := make(chan struct{})
+		go func() {
+			for i := 0; i < 5; i++ {
+				om.Set(i, i)
+			}
+			close(done)
+		}()
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Writer still blocked after subscriber canceled")
+		}
+	})
+
+	t.Run("Block Subscriber Reads Map", func(t *testing.T) {
+		om := NewOrderedMap()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := om.SubscribeWithOptions(ctx, &SubscribeOptions{Buffer: 1, Policy: Block})
+
+		const n = 50
+		done := make(chan struct{})
+		go func() {
+			for i := 0; i < n; i++ {
+				om.Set(i, i)
+			}
+			close(done)
+		}()
+		for i := 0; i < n; i++ {
+			ev := <-ch
+			time.Sleep(time.Millisecond)
+			if _, ok := om.Get(ev.Key); !ok {
+				t.Fatalf("Expected %v to be in the map", ev.Key)
+			}
+		}
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Writer blocked by a subscriber reading the map")
+		}
+	})
+
+	t.Run("Coalesce Policy", func(t *testing.T) {
+		s := &subscriber{policy: Coalesce, index: make(map[any]int)}
+		s.coalesce(Event{Type: EventInserted, Key: "a", Value: 1})
+		s.coalesce(Event{Type: EventUpdated, Key: "a", Value: 2, OldValue: 1})
+		s.coalesce(Event{Type: EventInserted, Key: "b", Value: 1, After: "a"})
+		s.coalesce(Event{Type: EventMoved, Key: "b", Value: 1})
+		s.coalesce(Event{Type: EventUpdated, Key: "b", Value: 3, OldValue: 1})
+		s.coalesce(Event{Type: EventInserted, Key: "c", Value: 1, After: "a"})
+		s.coalesce(Event{Type: EventDeleted, Key: "c", Value: 1})
+
+		expected := []Event{
+			{Type: EventInserted, Key: "a", Value: 2},
+			{Type: EventInserted, Key: "b", Value: 1, After: "a"},
+			{Type: EventMoved, Key: "b", Value: 3},
+			{Type: EventInserted, Key: "c", Value: 1, After: "a"},
+			{Type: EventDeleted, Key: "c", Value: 1},
+		}
+		if !reflect.DeepEqual(s.pending, expected) {
+			t.Errorf("Expected %v, got %v", expected, s.pending)
+		}
+	})
+
+	t.Run("Coalesce Mirror", func(t *testing.T) {
+		om := NewOrderedMap()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := om.SubscribeWithOptions(ctx, &SubscribeOptions{Buffer: 1, Policy: Coalesce})
+
+		for i := 0; i < 100; i++ {
+			om.Set(i%7, i)
+			if i%3 == 0 {
+				om.Delete((i + 1) % 7)
+			}
+			if i%5 == 0 {
+				om.MoveToFront((i + 2) % 7)
+			}
+			if i%11 == 0 {
+				om.PushFront(i%13, i)
+			}
+			if i%17 == 0 {
+				om.SortKeys()
+			}
+		}
+
+		// Contents and order are rebuilt from the events alone.
+		mirror := NewOrderedMap()
+		place := func(ev Event) {
+			if ev.After == nil {
+				mirror.MoveToFront(ev.Key)
+			} else {
+				mirror.MoveAfter(mirror.GetElement(ev.Key), mirror.GetElement(ev.After))
+			}
+		}
+		timeout := time.After(2 * time.Second)
+		for !mirror.Equal(om) {
+			select {
+			case ev := <-ch:
+				switch ev.Type {
+				case EventInserted, EventMoved:
+					mirror.Set(ev.Key, ev.Value)
+					place(ev)
+				case EventUpdated:
+					mirror.Set(ev.Key, ev.Value)
+				case EventDeleted:
+					mirror.Delete(ev.Key)
+				case EventCleared:
+					mirror.Clear()
+				}
+			case <-timeout:
+				t.Fatalf("Mirror %v never converged to %v", mirror, om)
+			}
+		}
+	})
+
+	t.Run("Coalesce Updates And Clear", func(t *testing.T) {
+		s := &subscriber{policy: Coalesce, index: make(map[any]int)}
+		s.coalesce(Event{Type: EventUpdated, Key: "a", Value: 2, OldValue: 1})
+		s.coalesce(Event{Type: EventUpdated, Key: "a", Value: 3, OldValue: 2})
+		if ev := s.pending[0]; len(s.pending) != 1 || ev.Value != 3 || ev.OldValue != 1 {
+			t.Errorf("Expected merged update 1->3, got %v", ev)
+		}
+		s.coalesce(Event{Type: EventCleared})
+		if len(s.pending) != 1 || s.pending[0].Type != EventCleared {
+			t.Errorf("Expected only Cleared pending, got %v", s.pending)
+		}
+	})
+}
+
+func TestOrderedMap_SubscribeConcurrent(t *testing.T) {
+	om := NewOrderedMap()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	for _, policy := range []OverflowPolicy{DropNewest, Block, Coalesce} {
+		ch := om.SubscribeWithOptions(ctx, &SubscribeOptions{Buffer: 4, Policy: policy})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range ch {
+			}
+		}()
+	}
+
+	var writers sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		writers.Add(1)
+		go func(base int) {
+			defer writers.Done()
+			for i := 0; i < 200; i++ {
+				om.Set(base*1000+i, i)
+				om.Delete(base*1000 + i - 1)
+			}
+		}(g)
+	}
+	writers.Wait()
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,332 @@
+package orderedmap
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// ArrayStyle selects how Flatten writes the index of a slice element.
+type ArrayStyle int
+
+const (
+	// ArrayIndex writes indexes as ordinary path segments: items.0.name
+	ArrayIndex ArrayStyle = iota
+	// ArrayBracket writes indexes in brackets: items[0].name
+	ArrayBracket
+)
+
+// FlattenOptions configures Flatten.
+type FlattenOptions struct {
+	// Arrays is the style used for []any elements. The default is ArrayIndex.
+	Arrays ArrayStyle
+}
+
+// Flatten returns a new OrderedMap with one entry per leaf value, keyed by
+// the path to the leaf joined with sep. Nested *OrderedMap values and []any
+// slices are descended into; every other value, including empty maps and
+// slices, is a leaf. The entries follow a depth-first walk of the original
+// order. If sep is empty, "." is used. If opts is nil, default options are
+// used.
+//
+// Keys are formatted with fmt.Sprint. A backslash, "[" and every character
+// of sep inside a key are escaped with a backslash, so Unflatten can restore
+// keys that contain the separator. Flatten returns an error if the nested
+// values form a cycle. This method is thread-safe.
+//
+// Example:
+//
+//	// {db: {host: localhost, ports: [5432, 5433]}}
+//	flat, err := config.Flatten(".", nil)
+//	// {db.host: localhost, db.ports.0: 5432, db.ports.1: 5433}
+//	flat, err = config.Flatten("_", &FlattenOptions{Arrays: ArrayBracket})
+//	// {db_host: localhost, db_ports[0]: 5432, db_ports[1]: 5433}
+func (om *OrderedMap) Flatten(sep string, opts *FlattenOptions) (*OrderedMap, error) {
+	if sep == "" {
+		sep = "."
+	}
+	if opts == nil {
+		opts = &FlattenOptions{}
+	}
+	f := &flattener{
+		sep:     sep,
+		opts:    opts,
+		out:     NewOrderedMap(),
+		walking: make(map[cloneRef]bool),
+	}
+	if err := f.flattenMap("", om, true); err != nil {
+		return nil, err
+	}
+	return f.out, nil
+}
+
+type flattener struct {
+	sep     string
+	opts    *FlattenOptions
+	out     *OrderedMap
+	walking map[cloneRef]bool // Containers on the current path, to detect cycles
+}
+
+func (f *flattener) flattenMap(prefix string, om *OrderedMap, root bool) error {
+	// Check for a cycle before locking, since om may already be read-locked
+	// further up the path.
+	ref := cloneRef{typ: reflect.TypeOf(om), ptr: unsafe.Pointer(om)}
+	if f.walking[ref] {
+		return fmt.Errorf("cycle detected at %q", prefix)
+	}
+	f.walking[ref] = true
+	defer delete(f.walking, ref)
+
+	om.rlock()
+	defer om.runlock()
+
+	if om.length == 0 && !root {
+		_ = f.out.set(prefix, om)
+		return nil
+	}
+	for current := om.head; current != nil; current = current.next {
+		path := escapePathKey(fmt.Sprint(current.Key), f.sep)
+		if !root {
+			path = prefix + f.sep + path
+		}
+		if err := f.flattenValue(path, current.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *flattener) flattenValue(path string, v any) error {
+	switch x := v.(type) {
+	case *OrderedMap:
+		if x != nil {
+			return f.flattenMap(path, x, false)
+		}
+	case []any:
+		if len(x) > 0 {
+			ref := cloneRef{typ: reflect.TypeOf(x), ptr: unsafe.Pointer(unsafe.SliceData(x)), n: len(x)}
+			if f.walking[ref] {
+				return fmt.Errorf("cycle detected at %q", path)
+			}
+			f.walking[ref] = true
+			defer delete(f.walking, ref)
+
+			for i, elem := range x {
+				var elemPath string
+				if f.opts.Arrays == ArrayBracket {
+					elemPath = path + "[" + strconv.Itoa(i) + "]"
+				} else {
+					elemPath = path + f.sep + strconv.Itoa(i)
+				}
+				if err := f.flattenValue(elemPath, elem); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	_ = f.out.set(path, v)
+	return nil
+}
+
+// escapePathKey escapes the characters of key that Unflatten would otherwise
+// read as path syntax.
+func escapePathKey(key, sep string) string {
+	if !strings.ContainsAny(key, sep+`\[`) {
+		return key
+	}
+	var sb strings.Builder
+	for _, r := range key {
+		if r == '\\' || r == '[' || strings.ContainsRune(sep, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Unflatten reverses Flatten: every key is split on sep into a path, and the
+// value is stored at that path in nested *OrderedMap values, created in the
+// order the paths first appear. Backslash escapes in keys are resolved.
+//
+// Bracketed indexes (items[0]) always create []any slices. Plain segments
+// create slices only when a nested map ends up with exactly the keys "0",
+// "1", ... in order, so a map with such keys does not survive a round trip
+// through ArrayIndex style. Indexes must appear in increasing order without
+// gaps. If sep is empty, "." is used.
+//
+// Unflatten returns an error if a key is not a string, is malformed, or if a
+// path runs through an existing leaf value (a=1 and a.b=2).
+// This method is thread-safe.
+//
+// Example:
+//
+//	flat := NewOrderedMap()
+//	flat.Set("db.host", "localhost")
+//	flat.Set("db.ports[0]", 5432)
+//	config, err := flat.Unflatten(".")
+//	// {db: {host: localhost, ports: [5432]}}
+func (om *OrderedMap) Unflatten(sep string) (*OrderedMap, error) {
+	if sep == "" {
+		sep = "."
+	}
+	u := &unflattener{root: NewOrderedMap(), created: make(map[*OrderedMap]bool)}
+	u.created[u.root] = false
+
+	om.rlock()
+	defer om.runlock()
+
+	for current := om.head; current != nil; current = current.next {
+		key, ok := current.Key.(string)
+		if !ok {
+			return nil, fmt.Errorf("key %v is not a string", current.Key)
+		}
+		path, err := parsePath(key, sep)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+		if err := u.insert(path, current.Value); err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+	}
+	return u.build(u.root).(*OrderedMap), nil
+}
+
+// pathSegment is one step of a flattened key.
+type pathSegment struct {
+	name  string
+	index bool // Whether the segment was written in brackets
+}
+
+// parsePath splits a flattened key into its segments.
+func parsePath(key, sep string) ([]pathSegment, error) {
+	var (
+		path    []pathSegment
+		sb      strings.Builder
+		bracket bool // Whether the last segment was a bracketed index
+	)
+	for i := 0; i < len(key); {
+		switch {
+		case key[i] == '\\':
+			if i+1 == len(key) {
+				return nil, fmt.Errorf("trailing escape character")
+			}
+			i++
+			fallthrough
+		default:
+			if bracket {
+				return nil, fmt.Errorf("unexpected %q after index", key[i:])
+			}
+			sb.WriteByte(key[i])
+			i++
+		case strings.HasPrefix(key[i:], sep):
+			if !bracket {
+				path = append(path, pathSegment{name: sb.String()})
+			}
+			sb.Reset()
+			bracket = false
+			i += len(sep)
+		case key[i] == '[':
+			end := strings.IndexByte(key[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated index")
+			}
+			index := key[i+1 : i+end]
+			if _, err := strconv.Atoi(index); err != nil || index[0] == '-' || index[0] == '+' {
+				return nil, fmt.Errorf("invalid index %q", index)
+			}
+			if !bracket {
+				path = append(path, pathSegment{name: sb.String()})
+				sb.Reset()
+			}
+			path = append(path, pathSegment{name: index, index: true})
+			bracket = true
+			i += end + 1
+		}
+	}
+	if !bracket {
+		path = append(path, pathSegment{name: sb.String()})
+	}
+	return path, nil
+}
+
+type unflattener struct {
+	root *OrderedMap
+	// created holds the maps built by Unflatten, and whether they were
+	// addressed with bracketed indexes.
+	created map[*OrderedMap]bool
+}
+
+func (u *unflattener) insert(path []pathSegment, value any) error {
+	parent := u.root
+	for i, seg := range path {
+		node, exists := parent.nodeMap[seg.name]
+		if !exists && seg.index && strconv.Itoa(parent.length) != seg.name {
+			return fmt.Errorf("index %s out of order", seg.name)
+		}
+		if i == len(path)-1 {
+			if exists {
+				return fmt.Errorf("duplicate or conflicting path")
+			}
+			_ = parent.set(seg.name, value)
+			return nil
+		}
+
+		var child *OrderedMap
+		if exists {
+			m, ok := node.Value.(*OrderedMap)
+			if _, isCreated := u.created[m]; !ok || !isCreated {
+				return fmt.Errorf("path runs through the value at %q", seg.name)
+			}
+			child = m
+		} else {
+			child = NewOrderedMap()
+			u.created[child] = false
+			_ = parent.set(seg.name, child)
+		}
+		if path[i+1].index {
+			u.created[child] = true
+		} else if u.created[child] {
+			return fmt.Errorf("mixed index and key segments under %q", seg.name)
+		}
+		parent = child
+	}
+	return nil
+}
+
+// build converts the created maps that represent slices into []any.
+func (u *unflattener) build(v any) any {
+	om, ok := v.(*OrderedMap)
+	if !ok {
+		return v
+	}
+	bracketed, isCreated := u.created[om]
+	if !isCreated {
+		return v
+	}
+	for current := om.head; current != nil; current = current.next {
+		current.Value = u.build(current.Value)
+	}
+	if om == u.root || !(bracketed || isSequence(om)) {
+		return om
+	}
+	out := make([]any, 0, om.length)
+	for current := om.head; current != nil; current = current.next {
+		out = append(out, current.Value)
+	}
+	return out
+}
+
+// isSequence reports whether the keys of om are "0", "1", ... in order.
+func isSequence(om *OrderedMap) bool {
+	i := 0
+	for current := om.head; current != nil; current = current.next {
+		if current.Key != strconv.Itoa(i) {
+			return false
+		}
+		i++
+	}
+	return i > 0
+}
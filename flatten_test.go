@@ -0,0 +1,178 @@
+package orderedmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrderedMap_Flatten(t *testing.T) {
+	t.Run("Index Style", func(t *testing.T) {
+		db := NewOrderedMap()
+		db.Set("host", "localhost")
+		db.Set("ports", []any{5432, 5433})
+
+		item := NewOrderedMap()
+		item.Set("name", "widget")
+
+		om := NewOrderedMap()
+		om.Set("db", db)
+		om.Set("items", []any{item})
+		om.Set("debug", true)
+
+		flat, err := om.Flatten(".", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "{db.host: localhost, db.ports.0: 5432, db.ports.1: 5433, items.0.name: widget, debug: true}"
+		if flat.String() != expected {
+			t.Errorf("Expected %s, got %s", expected, flat.String())
+		}
+	})
+
+	t.Run("Bracket Style", func(t *testing.T) {
+		db := NewOrderedMap()
+		db.Set("host", "localhost")
+		db.Set("ports", []any{5432, 5433})
+
+		item := NewOrderedMap()
+		item.Set("name", "widget")
+
+		om := NewOrderedMap()
+		om.Set("db", db)
+		om.Set("items", []any{item})
+		om.Set("debug", true)
+
+		flat, err := om.Flatten("_", &FlattenOptions{Arrays: ArrayBracket})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "{db_host: localhost, db_ports[0]: 5432, db_ports[1]: 5433, items[0]_name: widget, debug: true}"
+		if flat.String() != expected {
+			t.Errorf("Expected %s, got %s", expected, flat.String())
+		}
+	})
+
+	t.Run("Escaping", func(t *testing.T) {
+		inner := NewOrderedMap()
+		inner.Set("b.c", 1)
+		inner.Set(`d\e[0]`, 2)
+		om := NewOrderedMap()
+		om.Set("a", inner)
+
+		flat, err := om.Flatten("", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys := flat.Keys()
+		if keys[0] != `a.b\.c` || keys[1] != `a.d\\e\[0]` {
+			t.Errorf("Unexpected keys %q", keys)
+		}
+	})
+
+	t.Run("Empty Containers", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set("m", NewOrderedMap())
+		om.Set("s", []any{})
+		flat, err := om.Flatten(".", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if flat.Len() != 2 || !flat.Has("m") || !flat.Has("s") {
+			t.Errorf("Expected empty containers as leaves, got %s", flat.String())
+		}
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		om := NewOrderedMap()
+		inner := NewOrderedMap()
+		inner.Set("back", om)
+		om.Set("inner", inner)
+		if _, err := om.Flatten(".", nil); err == nil {
+			t.Error("Expected error for a cycle")
+		}
+	})
+}
+
+func TestOrderedMap_Unflatten(t *testing.T) {
+	t.Run("Round Trip", func(t *testing.T) {
+		for _, style := range []ArrayStyle{ArrayIndex, ArrayBracket} {
+			db := NewOrderedMap()
+			db.Set("host", "localhost")
+			db.Set("ports", []any{5432, 5433})
+
+			item := NewOrderedMap()
+			item.Set("name", "widget")
+
+			original := NewOrderedMap()
+			original.Set("db", db)
+			original.Set("items", []any{item})
+			original.Set("debug", true)
+
+			flat, err := original.Flatten(".", &FlattenOptions{Arrays: style})
+			if err != nil {
+				t.Fatal(err)
+			}
+			restored, err := flat.Unflatten(".")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !original.Equal(restored) {
+				t.Errorf("Style %d: expected %s, got %s", style, original.String(), restored.String())
+			}
+		}
+	})
+
+	t.Run("Escaped Keys", func(t *testing.T) {
+		inner := NewOrderedMap()
+		inner.Set("b.c", 1)
+		inner.Set(`d\e[0]`, 2)
+		om := NewOrderedMap()
+		om.Set("a", inner)
+
+		flat, _ := om.Flatten("::", nil)
+		restored, err := flat.Unflatten("::")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !om.Equal(restored) {
+			t.Errorf("Expected %s, got %s", om.String(), restored.String())
+		}
+	})
+
+	t.Run("Nested Arrays", func(t *testing.T) {
+		flat := NewOrderedMap()
+		flat.Set("m[0][0]", 1)
+		flat.Set("m[0][1]", 2)
+		flat.Set("m[1][0]", 3)
+		restored, err := flat.Unflatten(".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, _ := restored.Get("m")
+		expected := []any{[]any{1, 2}, []any{3}}
+		if !reflect.DeepEqual(m, expected) {
+			t.Errorf("Expected %v, got %v", expected, m)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		cases := [][]Pair{
+			{{"a", 1}, {"a.b", 2}},
+			{{"a.b", 1}, {"a", 2}},
+			{{"a[1]", 1}},
+			{{"a[x]", 1}},
+			{{"a[0", 1}},
+			{{"a[0]b", 1}},
+			{{`a\`, 1}},
+			{{"a[0]", 1}, {"a.b", 2}},
+			{{1, 1}},
+		}
+		for _, pairs := range cases {
+			flat := NewOrderedMap()
+			flat.SetMany(pairs...)
+			if _, err := flat.Unflatten("."); err == nil {
+				t.Errorf("Expected error for %s", flat.String())
+			}
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package orderedmap
+
+// Reduce folds the map into a single value. fn is called for each element in
+// order with the accumulator returned by the previous call, starting with
+// initial. The read lock is held throughout, so fn must not modify the map.
+// This method is thread-safe.
+//
+// Example:
+//
+//	total := om.Reduce(0, func(acc, key, value any) any {
+//	    return acc.(int) + value.(int)
+//	})
+func (om *OrderedMap) Reduce(initial any, fn func(acc, key, value any) any) any {
+	om.rlock()
+	defer om.runlock()
+
+	acc := initial
+	for current := om.head; current != nil; current = current.next {
+		acc = fn(acc, current.Key, current.Value)
+	}
+	return acc
+}
+
+// Find returns the first element, in order, that satisfies predicate.
+// Returns false if there is none. predicate must not modify the map.
+// This method is thread-safe.
+//
+// Example:
+//
+//	key, user, ok := users.Find(func(key, value any) bool {
+//	    return value.(User).Email == email
+//	})
+func (om *OrderedMap) Find(predicate func(key, value any) bool) (key, value any, found bool) {
+	om.rlock()
+	defer om.runlock()
+
+	for current := om.head; current != nil; current = current.next {
+		if predicate(current.Key, current.Value) {
+			return current.Key, current.Value, true
+		}
+	}
+	return nil, nil, false
+}
+
+// FindLast returns the last element, in order, that satisfies predicate.
+// Returns false if there is none. predicate must not modify the map.
+// This method is thread-safe.
+//
+// Example:
+//
+//	key, status, ok := attempts.FindLast(func(key, value any) bool {
+//	    return value == "failed"
+//	})
+func (om *OrderedMap) FindLast(predicate func(key, value any) bool) (key, value any, found bool) {
+	om.rlock()
+	defer om.runlock()
+
+	for current := om.tail; current != nil; current = current.prev {
+		if predicate(current.Key, current.Value) {
+			return current.Key, current.Value, true
+		}
+	}
+	return nil, nil, false
+}
+
+// Any reports whether at least one element satisfies predicate. It stops at
+// the first match. predicate must not modify the map.
+// This method is thread-safe.
+//
+// Example:
+//
+//	hasErrors := results.Any(func(key, value any) bool {
+//	    return value.(Result).Err != nil
+//	})
+func (om *OrderedMap) Any(predicate func(key, value any) bool) bool {
+	_, _, found := om.Find(predicate)
+	return found
+}
+
+// All reports whether every element satisfies predicate. It is true for an
+// empty map and stops at the first mismatch. predicate must not modify the
+// map. This method is thread-safe.
+//
+// Example:
+//
+//	allDone := jobs.All(func(key, value any) bool {
+//	    return value.(Job).Done
+//	})
+func (om *OrderedMap) All(predicate func(key, value any) bool) bool {
+	_, _, found := om.Find(func(key, value any) bool {
+		return !predicate(key, value)
+	})
+	return !found
+}
+
+// Count returns the number of elements that satisfy predicate. predicate
+// must not modify the map. This method is thread-safe.
+//
+// Example:
+//
+//	active := users.Count(func(key, value any) bool {
+//	    return value.(User).Active
+//	})
+func (om *OrderedMap) Count(predicate func(key, value any) bool) int {
+	om.rlock()
+	defer om.runlock()
+
+	count := 0
+	for current := om.head; current != nil; current = current.next {
+		if predicate(current.Key, current.Value) {
+			count++
+		}
+	}
+	return count
+}
+
+// Partition splits the map in two new maps: the elements that satisfy
+// predicate and those that don't, both in the original order. predicate
+// must not modify the map. This method is thread-safe.
+//
+// Example:
+//
+//	passed, failed := results.Partition(func(key, value any) bool {
+//	    return value.(Result).Err == nil
+//	})
+func (om *OrderedMap) Partition(predicate func(key, value any) bool) (yes, no *OrderedMap) {
+	om.rlock()
+	defer om.runlock()
+
+	yes, no = om.derive(0), om.derive(0)
+	for current := om.head; current != nil; current = current.next {
+		if predicate(current.Key, current.Value) {
+			_ = yes.setNormalized(current.Key, current.Value)
+		} else {
+			_ = no.setNormalized(current.Key, current.Value)
+		}
+	}
+	return yes, no
+}
+
+// GroupBy groups the elements by the key fn returns for each of them. The
+// result maps each group key to an *OrderedMap of the group's elements in
+// their original order; groups appear in the order they were first seen.
+// Elements for which fn returns nil are left out. fn must not modify the
+// map. This method is thread-safe.
+//
+// Example:
+//
+//	byDept := employees.GroupBy(func(key, value any) any {
+//	    return value.(Employee).Department
+//	})
+//	engineering, _ := byDept.Get("engineering")
+func (om *OrderedMap) GroupBy(fn func(key, value any) any) *OrderedMap {
+	om.rlock()
+	defer om.runlock()
+
+	groups := NewOrderedMap()
+	for current := om.head; current != nil; current = current.next {
+		groupKey := fn(current.Key, current.Value)
+		if groupKey == nil {
+			continue
+		}
+		var group *OrderedMap
+		if node, exists := groups.nodeMap[groupKey]; exists {
+			group = node.Value.(*OrderedMap)
+		} else {
+			group = om.derive(0)
+			_ = groups.set(groupKey, group)
+		}
+		_ = group.setNormalized(current.Key, current.Value)
+	}
+	return groups
+}
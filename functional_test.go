@@ -0,0 +1,114 @@
+package orderedmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func isEven(key, value any) bool {
+	return value.(int)%2 == 0
+}
+
+func TestOrderedMap_Reduce(t *testing.T) {
+	om := NewOrderedMap()
+	for i, k := range []string{"a", "b", "c", "d", "e"} {
+		om.Set(k, i+1)
+	}
+	sum := om.Reduce(0, func(acc, key, value any) any { return acc.(int) + value.(int) })
+	if sum != 15 {
+		t.Errorf("Expected 15, got %v", sum)
+	}
+	keys := om.Reduce("", func(acc, key, value any) any { return acc.(string) + key.(string) })
+	if keys != "abcde" {
+		t.Errorf("Expected keys in order, got %v", keys)
+	}
+	if v := NewOrderedMap().Reduce("init", nil); v != "init" {
+		t.Errorf("Expected initial value for empty map, got %v", v)
+	}
+}
+
+func TestOrderedMap_Find(t *testing.T) {
+	om := NewOrderedMap()
+	for i, k := range []string{"a", "b", "c", "d", "e"} {
+		om.Set(k, i+1)
+	}
+	if k, v, ok := om.Find(isEven); !ok || k != "b" || v != 2 {
+		t.Errorf("Unexpected Find (%v, %v, %v)", k, v, ok)
+	}
+	if k, v, ok := om.FindLast(isEven); !ok || k != "d" || v != 4 {
+		t.Errorf("Unexpected FindLast (%v, %v, %v)", k, v, ok)
+	}
+	never := func(key, value any) bool { return false }
+	if _, _, ok := om.Find(never); ok {
+		t.Error("Expected Find to fail")
+	}
+	if _, _, ok := om.FindLast(never); ok {
+		t.Error("Expected FindLast to fail")
+	}
+}
+
+func TestOrderedMap_AnyAllCount(t *testing.T) {
+	om := NewOrderedMap()
+	for i, k := range []string{"a", "b", "c", "d", "e"} {
+		om.Set(k, i+1)
+	}
+	positive := func(key, value any) bool { return value.(int) > 0 }
+
+	if !om.Any(isEven) || om.All(isEven) || !om.All(positive) {
+		t.Error("Unexpected Any/All result")
+	}
+	if n := om.Count(isEven); n != 2 {
+		t.Errorf("Expected 2, got %d", n)
+	}
+
+	calls := 0
+	om.Any(func(key, value any) bool {
+		calls++
+		return true
+	})
+	if calls != 1 {
+		t.Errorf("Expected Any to stop at the first match, got %d calls", calls)
+	}
+
+	empty := NewOrderedMap()
+	if empty.Any(positive) || !empty.All(isEven) || empty.Count(positive) != 0 {
+		t.Error("Unexpected results for an empty map")
+	}
+}
+
+func TestOrderedMap_Partition(t *testing.T) {
+	om := NewOrderedMap()
+	for i, k := range []string{"a", "b", "c", "d", "e"} {
+		om.Set(k, i+1)
+	}
+
+	yes, no := om.Partition(isEven)
+	if yes.String() != "{b: 2, d: 4}" {
+		t.Errorf("Unexpected yes %s", yes.String())
+	}
+	if no.String() != "{a: 1, c: 3, e: 5}" {
+		t.Errorf("Unexpected no %s", no.String())
+	}
+}
+
+func TestOrderedMap_GroupBy(t *testing.T) {
+	om := NewOrderedMap()
+	om.Set("carol", "ops")
+	om.Set("alice", "eng")
+	om.Set("dave", "ops")
+	om.Set("bob", "eng")
+	om.Set("eve", nil)
+
+	groups := om.GroupBy(func(key, value any) any { return value })
+	if !reflect.DeepEqual(groups.Keys(), []any{"ops", "eng"}) {
+		t.Errorf("Expected groups in first-seen order, got %v", groups.Keys())
+	}
+	ops, _ := groups.Get("ops")
+	if !reflect.DeepEqual(ops.(*OrderedMap).Keys(), []any{"carol", "dave"}) {
+		t.Errorf("Unexpected ops group %v", ops)
+	}
+	eng, _ := groups.Get("eng")
+	if !reflect.DeepEqual(eng.(*OrderedMap).Keys(), []any{"alice", "bob"}) {
+		t.Errorf("Unexpected eng group %v", eng)
+	}
+}
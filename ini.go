@@ -0,0 +1,351 @@
+package orderedmap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultSection is the name of the section that holds the keys of an INI
+// file that come before the first section header.
+const DefaultSection = ""
+
+// INIOptions configures ParseINIWithOptions.
+type INIOptions struct {
+	// OnDuplicate is the policy for a key that appears more than once in a
+	// section. The default is KeepLast.
+	OnDuplicate Collision
+	// Combine merges the existing and the incoming value of a duplicate key.
+	// It is required when OnDuplicate is CombineValues.
+	Combine func(key, existing, incoming any) any
+	// CaseInsensitive makes section and key names case-insensitive. The
+	// returned maps normalize names to lower case.
+	CaseInsensitive bool
+}
+
+// ParseINI reads an INI file into a new OrderedMap with one nested
+// *OrderedMap of string values per section, in file order. Keys before the
+// first section header go into the DefaultSection. Lines starting with ";"
+// or "#" are comments. Entries are "key = value" or "key: value"; a value
+// may be double-quoted to keep surrounding whitespace or ";" and "#", which
+// otherwise start an inline comment when preceded by whitespace.
+//
+// If a section header appears more than once, the sections are merged. If a
+// key appears more than once in a section, the last value wins.
+//
+// The returned maps remember the comments, blank lines and original text of
+// the file, so WriteINI reproduces it except where entries were changed.
+//
+// Example:
+//
+//	cfg, err := ParseINI(f)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	db, _ := cfg.Get("database")
+//	host, _ := db.(*OrderedMap).Get("host")
+func ParseINI(r io.Reader) (*OrderedMap, error) {
+	return ParseINIWithOptions(r, nil)
+}
+
+// ParseINIWithOptions is like ParseINI but allows duplicate keys and case
+// sensitivity to be configured. If opts is nil, default options are used.
+//
+// Example:
+//
+//	// Collect repeated keys into a []string
+//	cfg, err := ParseINIWithOptions(f, &INIOptions{
+//	    OnDuplicate: CombineValues,
+//	    Combine: func(key, existing, incoming any) any {
+//	        if list, ok := existing.([]string); ok {
+//	            return append(list, incoming.(string))
+//	        }
+//	        return []string{existing.(string), incoming.(string)}
+//	    },
+//	})
+func ParseINIWithOptions(r io.Reader, opts *INIOptions) (*OrderedMap, error) {
+	if opts == nil {
+		opts = &INIOptions{}
+	}
+	if opts.OnDuplicate == CombineValues && opts.Combine == nil {
+		return nil, fmt.Errorf("combine function cannot be nil")
+	}
+	lines, err := splitLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	newMap := func() *OrderedMap {
+		if opts.CaseInsensitive {
+			return New(WithKeyNormalizer(lowerKey))
+		}
+		return NewOrderedMap()
+	}
+	root := newMap()
+	root.layout = newTextLayout("ini")
+
+	var (
+		section     *OrderedMap
+		sectionName = DefaultSection
+		pending     []string
+	)
+	// openSection makes name the current section and reports whether it is
+	// new.
+	openSection := func(name string) bool {
+		sectionName = name
+		if node, exists := root.lookup(name); exists {
+			section = node.Value.(*OrderedMap)
+			return false
+		}
+		section = newMap()
+		section.layout = newTextLayout("ini")
+		_ = root.set(name, section)
+		return true
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == ';' || trimmed[0] == '#' {
+			pending = append(pending, line)
+			continue
+		}
+
+		if trimmed[0] == '[' {
+			end := strings.IndexByte(trimmed, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated section header", i+1)
+			}
+			if !isINIComment(trimmed[end+1:]) {
+				return nil, fmt.Errorf("line %d: unexpected %q after section header", i+1, trimmed[end+1:])
+			}
+			name := strings.TrimSpace(trimmed[1:end])
+			if openSection(name) {
+				root.layout.entries[root.normalizeKey(name)] = &layoutEntry{before: pending, raw: []string{line}}
+				pending = nil
+			}
+			// A repeated header is dropped; the lines above it stay with the
+			// next entry.
+			continue
+		}
+
+		rest := strings.TrimLeft(line, " \t")
+		sep := strings.IndexAny(rest, "=:")
+		if sep < 0 {
+			return nil, fmt.Errorf("line %d: missing '=' in %q", i+1, line)
+		}
+		key := strings.TrimSpace(rest[:sep])
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty key", i+1)
+		}
+		raw := rest[sep+1:]
+		lead, value, comment := parseINIValue(raw)
+
+		if section == nil {
+			openSection(DefaultSection)
+		}
+		layoutKey := section.normalizeKey(key)
+		var stored any = value
+		if node, exists := section.lookup(key); exists {
+			switch opts.OnDuplicate {
+			case KeepFirst:
+				stored = node.Value
+			case CombineValues:
+				stored = opts.Combine(node.Key, node.Value, value)
+			case FailOnCollision:
+				return nil, fmt.Errorf("line %d: %w: %s in section %q", i+1, ErrKeyCollision, key, sectionName)
+			}
+			// The repeated line is kept with the first one, so an unchanged
+			// entry is written back as it was read.
+			e := section.layout.entries[layoutKey]
+			e.raw = append(append(e.raw, pending...), line)
+			e.value = stored
+		} else {
+			section.layout.entries[layoutKey] = &layoutEntry{
+				before:  pending,
+				raw:     []string{line},
+				value:   value,
+				head:    line[:len(line)-len(raw)] + lead,
+				comment: comment,
+			}
+		}
+		pending = nil
+		_ = section.set(key, stored)
+	}
+	root.layout.trailer = pending
+	return root, nil
+}
+
+// parseINIValue splits the text after the separator into the whitespace
+// before the value, the value and the trailing comment, including the
+// whitespace before it. If the text is only whitespace and a comment, the
+// value is empty and the whitespace is both the lead and part of the comment.
+func parseINIValue(raw string) (lead, value, comment string) {
+	text := strings.TrimLeft(raw, " \t")
+	lead = raw[:len(raw)-len(text)]
+	if lead != "" && text != "" && (text[0] == ';' || text[0] == '#') {
+		return lead, "", raw
+	}
+	if strings.HasPrefix(text, `"`) {
+		for j := 1; j < len(text); j++ {
+			if text[j] == '"' && isINIComment(text[j+1:]) {
+				return lead, text[1:j], text[j+1:]
+			}
+		}
+	}
+	value = text
+	for j := 1; j < len(text); j++ {
+		if (text[j] == ';' || text[j] == '#') && (text[j-1] == ' ' || text[j-1] == '\t') {
+			value = text[:j]
+			break
+		}
+	}
+	value = strings.TrimRight(value, " \t")
+	return lead, value, text[len(value):]
+}
+
+// isINIComment reports whether s is blank or an inline comment.
+func isINIComment(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || s[0] == ';' || s[0] == '#'
+}
+
+// WriteINI writes om to w in INI format. Every *OrderedMap value of om is a
+// section, written as a [name] header followed by its entries; the
+// DefaultSection and any other top-level values are written first, without
+// a header. Values are formatted with fmt.Sprint and double-quoted when
+// needed; []string and []any values are written as one line per element.
+//
+// If om was returned by ParseINI, the comments and blank lines of the file
+// are written back in place, and unchanged entries keep their original
+// text, so changing one value changes one line. An error is returned for
+// names or values that cannot be represented, such as values containing line
+// breaks. This function is thread-safe.
+//
+// Example:
+//
+//	db, _ := cfg.Get("database")
+//	db.(*OrderedMap).Set("port", 5433)
+//	if err := WriteINI(f, cfg); err != nil {
+//	    log.Fatal(err)
+//	}
+func WriteINI(w io.Writer, om *OrderedMap) error {
+	om.rlock()
+	defer om.runlock()
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	layout := om.layoutFor("ini")
+
+	// Keys after a section header belong to that section, so everything
+	// without a header comes first.
+	if node, exists := om.lookup(DefaultSection); exists {
+		if section, ok := node.Value.(*OrderedMap); ok {
+			if err := writeINISection(bw, section); err != nil {
+				return err
+			}
+		}
+	}
+	for current := om.head; current != nil; current = current.next {
+		if _, ok := current.Value.(*OrderedMap); ok {
+			continue
+		}
+		line, err := encodeINIEntry(nil, current.Key, current.Value)
+		if err != nil {
+			return err
+		}
+		writeLines(bw, []string{line})
+	}
+
+	for current := om.head; current != nil; current = current.next {
+		section, ok := current.Value.(*OrderedMap)
+		if !ok || current.Key == om.normalizeKey(DefaultSection) {
+			continue
+		}
+		var e *layoutEntry
+		if layout != nil {
+			e = layout.entries[current.Key]
+		}
+		if e != nil {
+			writeLines(bw, e.before)
+			writeLines(bw, e.raw)
+		} else {
+			name := textValue(current.Key)
+			if name == "" || strings.ContainsAny(name, "]\r\n") {
+				return fmt.Errorf("invalid section name %q", name)
+			}
+			if cw.n > 0 || bw.Buffered() > 0 {
+				bw.WriteByte('\n')
+			}
+			writeLines(bw, []string{"[" + name + "]"})
+		}
+		if err := writeINISection(bw, section); err != nil {
+			return err
+		}
+	}
+	if layout != nil {
+		writeLines(bw, layout.trailer)
+	}
+	return bw.Flush()
+}
+
+func writeINISection(bw *bufio.Writer, section *OrderedMap) error {
+	section.rlock()
+	defer section.runlock()
+	return section.writeEntries(bw, section.layoutFor("ini"), encodeINIEntry)
+}
+
+// encodeINIEntry formats one entry, reusing the key and separator of the
+// original line if there is one.
+func encodeINIEntry(e *layoutEntry, key, value any) (string, error) {
+	name := textValue(key)
+	if name == "" || name != strings.TrimSpace(name) || strings.ContainsAny(name, "=:\r\n") || strings.ContainsRune("[;#", rune(name[0])) {
+		return "", fmt.Errorf("invalid key %q", name)
+	}
+
+	var values []string
+	switch v := value.(type) {
+	case []string:
+		values = v
+	case []any:
+		for _, elem := range v {
+			values = append(values, textValue(elem))
+		}
+	default:
+		values = []string{textValue(value)}
+	}
+	if len(values) == 0 {
+		values = []string{""}
+	}
+
+	lines := make([]string, len(values))
+	for i, s := range values {
+		if strings.ContainsAny(s, "\r\n") {
+			return "", fmt.Errorf("value of %s cannot contain a line break", name)
+		}
+		if s != strings.TrimSpace(s) || strings.ContainsAny(s, ";#") || strings.HasPrefix(s, `"`) {
+			s = `"` + s + `"`
+		}
+		switch {
+		case e == nil:
+			lines[i] = name + " = " + s
+		case i == 0:
+			lines[i] = e.head + s + e.comment
+		default:
+			lines[i] = e.head + s
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
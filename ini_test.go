@@ -0,0 +1,228 @@
+package orderedmap
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testINI = `; global settings
+name = demo
+debug=true
+
+# Database
+[database]
+host = localhost ; primary
+port: 5432
+password = "  secret; with spaces "
+
+[Servers]
+web = 10.0.0.1
+web = 10.0.0.2
+
+; trailing comment
+`
+
+func TestParseINI(t *testing.T) {
+	t.Run("Sections", func(t *testing.T) {
+		cfg, err := ParseINI(strings.NewReader(testINI))
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys := cfg.Keys()
+		if len(keys) != 3 || keys[0] != DefaultSection || keys[1] != "database" || keys[2] != "Servers" {
+			t.Fatalf("Unexpected sections %q", keys)
+		}
+
+		def, _ := cfg.Get(DefaultSection)
+		if def.(*OrderedMap).String() != "{name: demo, debug: true}" {
+			t.Errorf("Unexpected default section %s", def.(*OrderedMap).String())
+		}
+		db, _ := cfg.Get("database")
+		expected := []Pair{{"host", "localhost"}, {"port", "5432"}, {"password", "  secret; with spaces "}}
+		for i, key := range db.(*OrderedMap).Keys() {
+			value, _ := db.(*OrderedMap).Get(key)
+			if key != expected[i].Key || value != expected[i].Value {
+				t.Errorf("Expected %v=%q, got %v=%q", expected[i].Key, expected[i].Value, key, value)
+			}
+		}
+		servers, _ := cfg.Get("Servers")
+		if web, _ := servers.(*OrderedMap).Get("web"); web != "10.0.0.2" {
+			t.Errorf("Expected last value to win, got %v", web)
+		}
+	})
+
+	t.Run("Duplicate Policies", func(t *testing.T) {
+		cfg, err := ParseINIWithOptions(strings.NewReader(testINI), &INIOptions{OnDuplicate: KeepFirst})
+		if err != nil {
+			t.Fatal(err)
+		}
+		servers, _ := cfg.Get("Servers")
+		if web, _ := servers.(*OrderedMap).Get("web"); web != "10.0.0.1" {
+			t.Errorf("Expected first value, got %v", web)
+		}
+
+		cfg, err = ParseINIWithOptions(strings.NewReader(testINI), &INIOptions{
+			OnDuplicate: CombineValues,
+			Combine: func(key, existing, incoming any) any {
+				return []string{existing.(string), incoming.(string)}
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		servers, _ = cfg.Get("Servers")
+		if web, _ := servers.(*OrderedMap).Get("web"); !valuesEqual(web, []string{"10.0.0.1", "10.0.0.2"}) {
+			t.Errorf("Expected both values, got %v", web)
+		}
+
+		_, err = ParseINIWithOptions(strings.NewReader(testINI), &INIOptions{OnDuplicate: FailOnCollision})
+		if !errors.Is(err, ErrKeyCollision) {
+			t.Errorf("Expected ErrKeyCollision, got %v", err)
+		}
+	})
+
+	t.Run("Case Insensitive", func(t *testing.T) {
+		input := "[Server]\nHost = a\n[SERVER]\nhost = b\nPort = 1\n"
+		cfg, err := ParseINIWithOptions(strings.NewReader(input), &INIOptions{CaseInsensitive: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Len() != 1 {
+			t.Fatalf("Expected one section, got %d", cfg.Len())
+		}
+		server, _ := cfg.Get("sErVeR")
+		if server.(*OrderedMap).String() != "{host: b, port: 1}" {
+			t.Errorf("Unexpected section %s", server.(*OrderedMap).String())
+		}
+	})
+
+	t.Run("Comment After Separator", func(t *testing.T) {
+		cfg, err := ParseINI(strings.NewReader("k = ; note\nj = # note\nv =;value\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		def, _ := cfg.Get(DefaultSection)
+		section := def.(*OrderedMap)
+		if section.String() != "{k: , j: , v: ;value}" {
+			t.Errorf("Expected {k: , j: , v: ;value}, got %s", section.String())
+		}
+
+		section.Set("k", "x")
+		var buf bytes.Buffer
+		if err := WriteINI(&buf, cfg); err != nil {
+			t.Fatal(err)
+		}
+		expected := "k = x ; note\nj = # note\nv =;value\n"
+		if buf.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, input := range []string{"[open\n", "[a] b\n", "novalue\n", "= x\n"} {
+			if _, err := ParseINI(strings.NewReader(input)); err == nil {
+				t.Errorf("Expected error for %q", input)
+			}
+		}
+	})
+}
+
+func TestWriteINI(t *testing.T) {
+	t.Run("Round Trip", func(t *testing.T) {
+		cfg, err := ParseINI(strings.NewReader(testINI))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := WriteINI(&buf, cfg); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != testINI {
+			t.Errorf("Expected unchanged output, got:\n%s", buf.String())
+		}
+	})
+
+	t.Run("Minimal Diff", func(t *testing.T) {
+		cfg, _ := ParseINI(strings.NewReader(testINI))
+		db, _ := cfg.Get("database")
+		db.(*OrderedMap).Set("host", "db.internal")
+		db.(*OrderedMap).Delete("password")
+		servers, _ := cfg.Get("Servers")
+		servers.(*OrderedMap).Set("web", []string{"10.0.0.3", "10.0.0.4"})
+		cache := NewOrderedMap()
+		cache.Set("ttl", 60)
+		cfg.Set("cache", cache)
+
+		var buf bytes.Buffer
+		if err := WriteINI(&buf, cfg); err != nil {
+			t.Fatal(err)
+		}
+		expected := `; global settings
+name = demo
+debug=true
+
+# Database
+[database]
+host = db.internal ; primary
+port: 5432
+
+[Servers]
+web = 10.0.0.3
+web = 10.0.0.4
+
+[cache]
+ttl = 60
+
+; trailing comment
+`
+		if buf.String() != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+		}
+	})
+
+	t.Run("New Map", func(t *testing.T) {
+		section := NewOrderedMap()
+		section.Set("path", " /tmp ")
+		section.Set("note", "a#b")
+		cfg := NewOrderedMap()
+		cfg.Set("version", 2)
+		cfg.Set("paths", section)
+
+		var buf bytes.Buffer
+		if err := WriteINI(&buf, cfg); err != nil {
+			t.Fatal(err)
+		}
+		expected := "version = 2\n\n[paths]\npath = \" /tmp \"\nnote = \"a#b\"\n"
+		if buf.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, buf.String())
+		}
+
+		parsed, err := ParseINI(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths, _ := parsed.Get("paths")
+		if v, _ := paths.(*OrderedMap).Get("path"); v != " /tmp " {
+			t.Errorf("Expected value to round trip, got %q", v)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		section := NewOrderedMap()
+		section.Set("key", "line\nbreak")
+		cfg := NewOrderedMap()
+		cfg.Set("s", section)
+		if err := WriteINI(&bytes.Buffer{}, cfg); err == nil {
+			t.Error("Expected error for a line break")
+		}
+
+		section = NewOrderedMap()
+		section.Set("a=b", "x")
+		cfg.Set("s", section)
+		if err := WriteINI(&bytes.Buffer{}, cfg); err == nil {
+			t.Error("Expected error for an invalid key")
+		}
+	})
+}
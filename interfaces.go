@@ -0,0 +1,35 @@
+package orderedmap
+
+// ReadableMap is the read-only method set shared by the ordered maps of this
+// package and their read-only views. Iteration methods (Keys, Values, Range)
+// visit entries in the map's order, and First and Last return the entries at
+// either end of that order.
+type ReadableMap interface {
+	Get(key any) (any, bool)
+	Has(key any) bool
+	Len() int
+	Keys() []any
+	Values() []any
+	Range(f func(key, value any) bool)
+	First() (key, value any, exists bool)
+	Last() (key, value any, exists bool)
+}
+
+// Map is a ReadableMap that can be modified. Code that doesn't depend on a
+// particular map type should be written against Map; the orderedmaptest
+// package checks that an implementation honors its contract.
+type Map interface {
+	ReadableMap
+	Set(key, value any) error
+	Delete(key any) error
+	Clear()
+}
+
+var (
+	_ Map = (*OrderedMap)(nil)
+	_ Map = (*LocalMap)(nil)
+
+	_ ReadableMap = (*Snapshot)(nil)
+	_ ReadableMap = (*ReadTx)(nil)
+	_ ReadableMap = (*DurableOrderedMap)(nil)
+)
@@ -0,0 +1,89 @@
+package orderedmap
+
+import "fmt"
+
+// LocalMap is an OrderedMap without any locking, for maps confined to a
+// single goroutine such as per-request state. It has the same methods as
+// OrderedMap and behaves identically, but it must not be used by more than
+// one goroutine at a time. Use Sync to hand its contents over to a
+// thread-safe OrderedMap.
+//
+// Example:
+//
+//	lm := NewLocal()
+//	lm.Set("key", "value")
+type LocalMap struct {
+	*OrderedMap
+}
+
+// NewLocal creates an empty LocalMap. Options are applied as for New;
+// WithoutLocking is implied.
+//
+// Example:
+//
+//	lm := NewLocal(WithCapacity(64))
+func NewLocal(opts ...Option) *LocalMap {
+	return &LocalMap{OrderedMap: New(append(opts, WithoutLocking())...)}
+}
+
+// Set adds a new key-value pair to the map or updates an existing one.
+// See OrderedMap.Set.
+func (lm *LocalMap) Set(key, value any) error {
+	if key == nil {
+		return fmt.Errorf("key cannot be nil")
+	}
+	return lm.set(key, value)
+}
+
+// Get retrieves the value associated with the given key.
+// See OrderedMap.Get.
+func (lm *LocalMap) Get(key any) (any, bool) {
+	if node, exists := lm.lookup(key); exists {
+		return node.Value, true
+	}
+	return nil, false
+}
+
+// Has checks if a key exists in the map. See OrderedMap.Has.
+func (lm *LocalMap) Has(key any) bool {
+	_, exists := lm.lookup(key)
+	return exists
+}
+
+// Delete removes the element with the given key from the map.
+// See OrderedMap.Delete.
+func (lm *LocalMap) Delete(key any) error {
+	if key == nil {
+		return fmt.Errorf("key cannot be nil")
+	}
+	if node, exists := lm.lookup(key); exists {
+		lm.remove(node)
+	}
+	return nil
+}
+
+// Len returns the number of elements in the map.
+func (lm *LocalMap) Len() int {
+	return lm.length
+}
+
+// Sync moves the contents of lm, including its options and hooks, into a new
+// thread-safe OrderedMap and returns it. lm is left empty and can be reused.
+//
+// Example:
+//
+//	lm := NewLocal()
+//	lm.Set("a", 1)
+//	shared := lm.Sync()
+//	go worker(shared)
+func (lm *LocalMap) Sync() *OrderedMap {
+	om := lm.OrderedMap
+	lm.OrderedMap = &OrderedMap{
+		nodeMap:   make(map[any]*Node),
+		nolock:    true,
+		maxLen:    om.maxLen,
+		normalize: om.normalize,
+	}
+	om.nolock = false
+	return om
+}
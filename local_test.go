@@ -0,0 +1,105 @@
+package orderedmap
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestLocalMap(t *testing.T) {
+	lm := NewLocal()
+
+	t.Run("Basic Operations", func(t *testing.T) {
+		if err := lm.Set(nil, 1); err == nil {
+			t.Error("Expected error for nil key")
+		}
+		if err := lm.Delete(nil); err == nil {
+			t.Error("Expected error for nil key")
+		}
+		lm.Set("a", 1)
+		lm.Set("b", 2)
+		lm.Set("c", 3)
+		lm.Set("a", 10)
+		lm.Delete("b")
+		lm.Delete("missing")
+
+		if lm.Len() != 2 || !lm.Has("a") || lm.Has("b") || lm.Has(nil) {
+			t.Errorf("Unexpected contents %s", lm.String())
+		}
+		if v, ok := lm.Get("a"); !ok || v != 10 {
+			t.Errorf("Expected a=10, got %v", v)
+		}
+		if _, ok := lm.Get("b"); ok {
+			t.Error("Expected b to be deleted")
+		}
+		if !reflect.DeepEqual(lm.Keys(), []any{"a", "c"}) {
+			t.Errorf("Unexpected keys %v", lm.Keys())
+		}
+	})
+
+	t.Run("Same Behavior As OrderedMap", func(t *testing.T) {
+		om := NewOrderedMap()
+		local := NewLocal()
+		for _, m := range []Map{om, local} {
+			for i := 0; i < 10; i++ {
+				m.Set(i, i*i)
+			}
+			m.Delete(3)
+			m.Set(0, "zero")
+		}
+		local.MoveToFront(9)
+		om.MoveToFront(9)
+		if om.String() != local.String() {
+			t.Errorf("Expected %s, got %s", om.String(), local.String())
+		}
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		bounded := NewLocal(WithMaxLen(2), WithKeyNormalizer(lowerKey))
+		bounded.Set("A", 1)
+		bounded.Set("b", 2)
+		bounded.Set("C", 3)
+		if !reflect.DeepEqual(bounded.Keys(), []any{"b", "c"}) || !bounded.Has("B") {
+			t.Errorf("Unexpected keys %v", bounded.Keys())
+		}
+	})
+}
+
+func TestLocalMap_Sync(t *testing.T) {
+	lm := NewLocal(WithMaxLen(100))
+	for i := 0; i < 10; i++ {
+		lm.Set(i, i)
+	}
+
+	om := lm.Sync()
+	if om.nolock {
+		t.Fatal("Expected the synced map to lock")
+	}
+	if lm.Len() != 0 || !lm.nolock || lm.maxLen != 100 {
+		t.Error("Expected the local map to be empty and keep its options")
+	}
+	if om.Len() != 10 || om.maxLen != 100 {
+		t.Errorf("Expected 10 elements, got %d", om.Len())
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(base int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				om.Set(base*100+i, i)
+				om.Get(i)
+			}
+		}(g + 1)
+	}
+	wg.Wait()
+	if om.Len() != 90 {
+		t.Errorf("Expected 90 elements, got %d", om.Len())
+	}
+
+	lm.Set("reused", true)
+	if om.Has("reused") {
+		t.Error("Expected the local map to be independent after Sync")
+	}
+}
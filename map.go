@@ -11,10 +11,11 @@ import (
 // Node represents a node in the doubly linked list that maintains the order of elements.
 // Each node contains a key-value pair and pointers to the previous and next nodes.
 type Node struct {
-	Key   any   // The key of the key-value pair
-	Value any   // The value associated with the key
-	prev  *Node // Pointer to the previous node
-	next  *Node // Pointer to the next node
+	Key   any    // The key of the key-value pair
+	Value any    // The value associated with the key
+	prev  *Node  // Pointer to the previous node
+	next  *Node  // Pointer to the next node
+	stamp uint64 // Value of the map's seq when the node was last linked or unlinked
 }
 
 // OrderedMap is a thread-safe implementation of an ordered map data structure.
@@ -26,6 +27,23 @@ type OrderedMap struct {
 	tail    *Node         // Points to the last node in the list
 	nodeMap map[any]*Node // Maps keys to their corresponding nodes
 	length  int           // Number of elements in the map
+
+	observers *observers    // Change hooks and subscribers, nil until the first registration
+	stalled   []*subscriber // Block subscribers to wait for once the write lock is released
+	frozen    *frozenState  // State shared with snapshots, nil once the map has been written since
+	tx        *Tx           // Open read-write transaction, if any
+
+	waitMu sync.Mutex    // Guards waitc between concurrent readers
+	waitc  chan struct{} // Closed and cleared on the next change, nil if nobody waits
+
+	nolock    bool              // Set by WithoutLocking
+	maxLen    int               // Set by WithMaxLen, 0 means unbounded
+	normalize func(key any) any // Set by WithKeyNormalizer
+
+	layout *textLayout // Comments and formatting of a parsed text file, nil otherwise
+
+	seq     uint64 // Counts links and unlinks, to order node stamps for cursors
+	cleared uint64 // Value of seq when the map was last emptied by reset
 }
 
 // NewOrderedMap creates and initializes a new empty OrderedMap.
@@ -35,10 +53,10 @@ type OrderedMap struct {
 //
 //	om := NewOrderedMap()
 //	om.Set("key", "value")
+//
+// NewOrderedMap is equivalent to New without options.
 func NewOrderedMap() *OrderedMap {
-	return &OrderedMap{
-		nodeMap: make(map[any]*Node),
-	}
+	return New()
 }
 
 // Set adds a new key-value pair to the map or updates an existing one.
@@ -59,31 +77,9 @@ func (om *OrderedMap) Set(key, value any) error {
 		return fmt.Errorf("key cannot be nil")
 	}
 
-	om.mu.Lock()
-	defer om.mu.Unlock()
-
-	if node, exists := om.nodeMap[key]; exists {
-		node.Value = value
-		return nil
-	}
-
-	newNode := &Node{
-		Key:   key,
-		Value: value,
-	}
-
-	if om.tail == nil {
-		om.head = newNode
-		om.tail = newNode
-	} else {
-		newNode.prev = om.tail
-		om.tail.next = newNode
-		om.tail = newNode
-	}
-
-	om.nodeMap[key] = newNode
-	om.length++
-	return nil
+	om.lock()
+	defer om.unlock()
+	return om.set(key, value)
 }
 
 // Delete removes the element with the given key from the map.
@@ -101,33 +97,59 @@ func (om *OrderedMap) Delete(key any) error {
 		return fmt.Errorf("key cannot be nil")
 	}
 
-	om.mu.Lock()
-	defer om.mu.Unlock()
+	om.lock()
+	defer om.unlock()
 
-	node, exists := om.nodeMap[key]
-	if !exists {
-		return nil
+	if node, exists := om.lookup(key); exists {
+		om.remove(node)
 	}
+	return nil
+}
 
-	if node.prev != nil {
-		node.prev.next = node.next
-	} else {
-		om.head = node.next
+// MoveToFront moves the element with the given key to the front of the map
+// without changing its value. Returns false if the key is nil or doesn't exist.
+// This method is thread-safe.
+//
+// Example:
+//
+//	om.MoveToFront("urgent")
+func (om *OrderedMap) MoveToFront(key any) bool {
+	if key == nil {
+		return false
 	}
 
-	if node.next != nil {
-		node.next.prev = node.prev
-	} else {
-		om.tail = node.prev
+	om.lock()
+	defer om.unlock()
+
+	node, exists := om.lookup(key)
+	if !exists {
+		return false
 	}
+	om.moveAfter(node, nil)
+	return true
+}
 
-	delete(om.nodeMap, key)
-	om.length--
+// MoveToBack moves the element with the given key to the back of the map
+// without changing its value. Returns false if the key is nil or doesn't exist.
+// This method is thread-safe.
+//
+// Example:
+//
+//	om.MoveToBack("processed")
+func (om *OrderedMap) MoveToBack(key any) bool {
+	if key == nil {
+		return false
+	}
 
-	// Help GC by removing references
-	node.prev = nil
-	node.next = nil
-	return nil
+	om.lock()
+	defer om.unlock()
+
+	node, exists := om.lookup(key)
+	if !exists {
+		return false
+	}
+	om.moveAfter(node, om.tail)
+	return true
 }
 
 // Keys returns a slice containing all keys in the map in their insertion order.
@@ -141,8 +163,8 @@ func (om *OrderedMap) Delete(key any) error {
 //	    fmt.Println(key)
 //	}
 func (om *OrderedMap) Keys() []any {
-	om.mu.RLock()
-	defer om.mu.RUnlock()
+	om.rlock()
+	defer om.runlock()
 
 	keys := make([]any, 0, om.length)
 	current := om.head
@@ -164,8 +186,8 @@ func (om *OrderedMap) Keys() []any {
 //	    fmt.Println(value)
 //	}
 func (om *OrderedMap) Values() []any {
-	om.mu.RLock()
-	defer om.mu.RUnlock()
+	om.rlock()
+	defer om.runlock()
 
 	values := make([]any, 0, om.length)
 	current := om.head
@@ -187,13 +209,13 @@ func (om *OrderedMap) Values() []any {
 //	    return true // continue iteration
 //	})
 func (om *OrderedMap) Range(f func(key, value any) bool) {
-	om.mu.RLock()
+	om.rlock()
 	type entry struct{ key, value any }
 	entries := make([]entry, 0, om.length)
 	for current := om.head; current != nil; current = current.next {
 		entries = append(entries, entry{current.Key, current.Value})
 	}
-	om.mu.RUnlock()
+	om.runlock()
 
 	for _, e := range entries {
 		if !f(e.key, e.value) {
@@ -209,12 +231,9 @@ func (om *OrderedMap) Range(f func(key, value any) bool) {
 //
 //	om.Clear()
 func (om *OrderedMap) Clear() {
-	om.mu.Lock()
-	defer om.mu.Unlock()
-	om.nodeMap = make(map[any]*Node)
-	om.head = nil
-	om.tail = nil
-	om.length = 0
+	om.lock()
+	defer om.unlock()
+	om.reset()
 }
 
 // Get retrieves the value associated with the given key.
@@ -231,10 +250,10 @@ func (om *OrderedMap) Get(key any) (any, bool) {
 		return nil, false
 	}
 
-	om.mu.RLock()
-	defer om.mu.RUnlock()
+	om.rlock()
+	defer om.runlock()
 
-	if node, exists := om.nodeMap[key]; exists {
+	if node, exists := om.lookup(key); exists {
 		return node.Value, true
 	}
 	return nil, false
@@ -248,14 +267,18 @@ func (om *OrderedMap) Get(key any) (any, bool) {
 //
 //	fmt.Println(om.String()) // Output: {key1: value1, key2: value2}
 func (om *OrderedMap) String() string {
-	om.mu.RLock()
-	defer om.mu.RUnlock()
+	om.rlock()
+	defer om.runlock()
+	return formatNodes(om.head)
+}
 
+// formatNodes renders the list starting at head in the String format.
+func formatNodes(head *Node) string {
 	var buf bytes.Buffer
 	buf.WriteByte('{')
-	current := om.head
+	current := head
 	for current != nil {
-		if current != om.head {
+		if current != head {
 			buf.WriteString(", ")
 		}
 		fmt.Fprintf(&buf, "%v: %v", current.Key, current.Value)
@@ -273,8 +296,8 @@ func (om *OrderedMap) String() string {
 //	count := om.Len()
 //	fmt.Printf("Map contains %d elements\n", count)
 func (om *OrderedMap) Len() int {
-	om.mu.RLock()
-	defer om.mu.RUnlock()
+	om.rlock()
+	defer om.runlock()
 	return om.length
 }
 
@@ -292,27 +315,30 @@ func (om *OrderedMap) Has(key any) bool {
 		return false
 	}
 
-	om.mu.RLock()
-	defer om.mu.RUnlock()
+	om.rlock()
+	defer om.runlock()
 
-	_, exists := om.nodeMap[key]
+	_, exists := om.lookup(key)
 	return exists
 }
 
-// Copy creates a deep copy of the OrderedMap.
-// The new map contains copies of all key-value pairs in the same order.
+// Copy creates a shallow copy of the OrderedMap.
+// The new map has its own list of key-value pairs in the same order, but the
+// values themselves are shared: mutating a nested map or slice through the
+// copy also changes it in the original. Use Clone for a deep copy.
 // This method is thread-safe.
 //
 // Example:
 //
 //	newMap := om.Copy()
 func (om *OrderedMap) Copy() *OrderedMap {
-	om.mu.RLock()
-	defer om.mu.RUnlock()
+	om.rlock()
+	defer om.runlock()
 
-	newMap := NewOrderedMap()
+	newMap := om.derive(om.length)
+	newMap.layout = om.layout
 	for current := om.head; current != nil; current = current.next {
-		_ = newMap.set(current.Key, current.Value)
+		_ = newMap.setNormalized(current.Key, current.Value)
 	}
 	return newMap
 }
@@ -328,41 +354,49 @@ func (om *OrderedMap) Copy() *OrderedMap {
 //	    log.Fatal(err)
 //	}
 func (om *OrderedMap) MarshalJSON() ([]byte, error) {
-	om.mu.RLock()
-	defer om.mu.RUnlock()
+	om.rlock()
+	defer om.runlock()
+	return marshalNodes(om.head)
+}
 
+// marshalNodes encodes the list starting at head as a JSON object.
+func marshalNodes(head *Node) ([]byte, error) {
 	var buf bytes.Buffer
 	buf.WriteByte('{')
-	current := om.head
-	first := true
-	for current != nil {
-		if !first {
+	for current := head; current != nil; current = current.next {
+		if current != head {
 			buf.WriteByte(',')
 		}
-		first = false
-
-		keyStr, ok := current.Key.(string)
-		if !ok {
-			keyStr = fmt.Sprintf("%v", current.Key)
-		}
-		keyBytes, err := json.Marshal(keyStr)
-		if err != nil {
+		if err := appendJSONEntry(&buf, current.Key, current.Value); err != nil {
 			return nil, err
 		}
-		buf.Write(keyBytes)
-		buf.WriteByte(':')
-
-		valBytes, err := json.Marshal(current.Value)
-		if err != nil {
-			return nil, err
-		}
-		buf.Write(valBytes)
-		current = current.next
 	}
 	buf.WriteByte('}')
 	return buf.Bytes(), nil
 }
 
+// appendJSONEntry writes one "key":value member of a JSON object. Keys that
+// are not strings are formatted with %v.
+func appendJSONEntry(buf *bytes.Buffer, key, value any) error {
+	keyStr, ok := key.(string)
+	if !ok {
+		keyStr = fmt.Sprintf("%v", key)
+	}
+	keyBytes, err := json.Marshal(keyStr)
+	if err != nil {
+		return err
+	}
+	buf.Write(keyBytes)
+	buf.WriteByte(':')
+
+	valBytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	buf.Write(valBytes)
+	return nil
+}
+
 // UnmarshalJSON implements the json.Unmarshaler interface.
 // It populates the OrderedMap from a JSON object, maintaining the order of keys
 // as they appear in the JSON input.
@@ -385,13 +419,10 @@ func (om *OrderedMap) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("expected JSON object, got %v", tok)
 	}
 
-	om.mu.Lock()
-	defer om.mu.Unlock()
+	om.lock()
+	defer om.unlock()
 
-	om.nodeMap = make(map[any]*Node)
-	om.head = nil
-	om.tail = nil
-	om.length = 0
+	om.reset()
 
 	for dec.More() {
 		keyTok, err := dec.Token()
@@ -418,9 +449,21 @@ func (om *OrderedMap) set(key, value any) error {
 	if key == nil {
 		return fmt.Errorf("key cannot be nil")
 	}
+	return om.setNormalized(om.normalizeKey(key), value)
+}
 
+// setNormalized is set for a key that has already been passed through the
+// key normalizer, so the normalizer is not applied a second time.
+func (om *OrderedMap) setNormalized(key, value any) error {
+	if key == nil {
+		return fmt.Errorf("key cannot be nil")
+	}
+
+	om.prepareWrite()
 	if node, exists := om.nodeMap[key]; exists {
+		old := node.Value
 		node.Value = value
+		om.emit(Event{Type: EventUpdated, Key: key, Value: value, OldValue: old})
 		return nil
 	}
 
@@ -429,20 +472,135 @@ func (om *OrderedMap) set(key, value any) error {
 		Value: value,
 	}
 
-	if om.head == nil {
-		om.head = newNode
-		om.tail = newNode
-	} else {
-		newNode.prev = om.tail
-		om.tail.next = newNode
-		om.tail = newNode
-	}
-
+	om.pushBack(newNode)
 	om.nodeMap[key] = newNode
 	om.length++
+	om.emit(Event{Type: EventInserted, Key: key, Value: value, After: prevKey(newNode)})
+	om.evict()
 	return nil
 }
 
+// remove deletes node from the list and the node index.
+func (om *OrderedMap) remove(node *Node) {
+	om.prepareWrite()
+	om.unlink(node)
+	delete(om.nodeMap, node.Key)
+	om.length--
+
+	// The node keeps its prev and next pointers as a tombstone, so cursors
+	// positioned on it can still find their way back into the list. Live
+	// nodes never point at removed ones, so this doesn't keep garbage alive.
+	om.emit(Event{Type: EventDeleted, Key: node.Key, Value: node.Value})
+}
+
+// reset empties the map.
+func (om *OrderedMap) reset() {
+	if om.tx != nil {
+		// A rollback would bring the old nodes back into use.
+		om.prepareWrite()
+	}
+	// Otherwise the old nodes are never touched again, so snapshots can keep them.
+	om.frozen = nil
+	om.seq++
+	om.cleared = om.seq
+	om.nodeMap = make(map[any]*Node)
+	om.head = nil
+	om.tail = nil
+	om.length = 0
+	// The comments of a parsed file belong to its entries, which are gone.
+	om.layout = nil
+	om.emit(Event{Type: EventCleared})
+}
+
+// unlink detaches node from the linked list. The node index and length are
+// left to the caller. The node's own pointers are not modified.
+func (om *OrderedMap) unlink(node *Node) {
+	om.prepareWrite()
+	om.touch(node)
+	if node.prev != nil {
+		node.prev.next = node.next
+	} else {
+		om.head = node.next
+	}
+
+	if node.next != nil {
+		node.next.prev = node.prev
+	} else {
+		om.tail = node.prev
+	}
+}
+
+// pushBack links a detached node at the end of the list.
+func (om *OrderedMap) pushBack(node *Node) {
+	om.prepareWrite()
+	om.touch(node)
+	node.next = nil
+	node.prev = om.tail
+	if om.tail == nil {
+		om.head = node
+	} else {
+		om.tail.next = node
+	}
+	om.tail = node
+}
+
+// insertAfter links a detached node directly after mark, or at the start of
+// the list if mark is nil.
+func (om *OrderedMap) insertAfter(node, mark *Node) {
+	switch mark {
+	case nil:
+		om.pushFront(node)
+	case om.tail:
+		om.pushBack(node)
+	default:
+		om.prepareWrite()
+		om.touch(node)
+		node.prev = mark
+		node.next = mark.next
+		mark.next.prev = node
+		mark.next = node
+	}
+}
+
+// moveAfter relinks node directly after mark, or at the start of the list if
+// mark is nil, and reports the move. It is a no-op if node is already there.
+func (om *OrderedMap) moveAfter(node, mark *Node) {
+	if node == mark || node.prev == mark {
+		return
+	}
+	om.unlink(node)
+	om.insertAfter(node, mark)
+	om.emit(Event{Type: EventMoved, Key: node.Key, Value: node.Value, After: prevKey(node)})
+}
+
+// prevKey returns the key of the node before node, or nil if it is first.
+func prevKey(node *Node) any {
+	if node.prev == nil {
+		return nil
+	}
+	return node.prev.Key
+}
+
+// touch stamps node as linked or unlinked just now.
+func (om *OrderedMap) touch(node *Node) {
+	om.seq++
+	node.stamp = om.seq
+}
+
+// pushFront links a detached node at the start of the list.
+func (om *OrderedMap) pushFront(node *Node) {
+	om.prepareWrite()
+	om.touch(node)
+	node.prev = nil
+	node.next = om.head
+	if om.head == nil {
+		om.tail = node
+	} else {
+		om.head.prev = node
+	}
+	om.head = node
+}
+
 // First returns the first key-value pair in the map.
 // Returns nil values and false if the map is empty.
 // This method is thread-safe.
@@ -453,8 +611,8 @@ func (om *OrderedMap) set(key, value any) error {
 //	    fmt.Printf("First element - Key: %v, Value: %v\n", key, value)
 //	}
 func (om *OrderedMap) First() (key, value any, exists bool) {
-	om.mu.RLock()
-	defer om.mu.RUnlock()
+	om.rlock()
+	defer om.runlock()
 
 	if om.head == nil {
 		return nil, nil, false
@@ -472,8 +630,8 @@ func (om *OrderedMap) First() (key, value any, exists bool) {
 //	    fmt.Printf("Last element - Key: %v, Value: %v\n", key, value)
 //	}
 func (om *OrderedMap) Last() (key, value any, exists bool) {
-	om.mu.RLock()
-	defer om.mu.RUnlock()
+	om.rlock()
+	defer om.runlock()
 
 	if om.tail == nil {
 		return nil, nil, false
@@ -489,12 +647,12 @@ func (om *OrderedMap) Last() (key, value any, exists bool) {
 //	reversed := om.Reverse()
 //	fmt.Println(reversed.String())
 func (om *OrderedMap) Reverse() *OrderedMap {
-	om.mu.RLock()
-	defer om.mu.RUnlock()
+	om.rlock()
+	defer om.runlock()
 
-	reversed := NewOrderedMap()
+	reversed := om.derive(om.length)
 	for current := om.tail; current != nil; current = current.prev {
-		_ = reversed.set(current.Key, current.Value)
+		_ = reversed.setNormalized(current.Key, current.Value)
 	}
 	return reversed
 }
@@ -513,20 +671,22 @@ func (om *OrderedMap) Reverse() *OrderedMap {
 //	    return false
 //	})
 func (om *OrderedMap) Filter(predicate func(key, value any) bool) *OrderedMap {
-	om.mu.RLock()
-	defer om.mu.RUnlock()
+	om.rlock()
+	defer om.runlock()
 
-	filtered := NewOrderedMap()
+	filtered := om.derive(0)
 	for current := om.head; current != nil; current = current.next {
 		if predicate(current.Key, current.Value) {
-			_ = filtered.set(current.Key, current.Value)
+			_ = filtered.setNormalized(current.Key, current.Value)
 		}
 	}
 	return filtered
 }
 
 // Map creates a new OrderedMap by transforming each element using
-// the given mapping function.
+// the given mapping function. If two elements map to the same key, the later
+// value wins; elements mapped to a nil key are dropped. Use MapErr to report
+// errors and choose how collisions are handled.
 // This method is thread-safe.
 //
 // Example:
@@ -538,10 +698,10 @@ func (om *OrderedMap) Filter(predicate func(key, value any) bool) *OrderedMap {
 //	    return key, value
 //	})
 func (om *OrderedMap) Map(mapper func(key, value any) (any, any)) *OrderedMap {
-	om.mu.RLock()
-	defer om.mu.RUnlock()
+	om.rlock()
+	defer om.runlock()
 
-	mapped := NewOrderedMap()
+	mapped := om.derive(om.length)
 	for current := om.head; current != nil; current = current.next {
 		newKey, newValue := mapper(current.Key, current.Value)
 		_ = mapped.set(newKey, newValue)
@@ -582,8 +742,8 @@ func (om *OrderedMap) ToJSON(opts *JSONOptions) ([]byte, error) {
 		}
 	}
 
-	om.mu.RLock()
-	defer om.mu.RUnlock()
+	om.rlock()
+	defer om.runlock()
 
 	var buf bytes.Buffer
 	buf.WriteByte('{')
@@ -675,13 +835,10 @@ func (om *OrderedMap) FromJSON(data []byte, opts *JSONOptions) error {
 		return fmt.Errorf("expected JSON object, got %v", tok)
 	}
 
-	om.mu.Lock()
-	defer om.mu.Unlock()
+	om.lock()
+	defer om.unlock()
 
-	om.nodeMap = make(map[any]*Node)
-	om.head = nil
-	om.tail = nil
-	om.length = 0
+	om.reset()
 
 	for dec.More() {
 		keyTok, err := dec.Token()
@@ -1,6 +1,7 @@
 package orderedmap
 
 import (
+	"context"
 	"testing"
 )
 
@@ -91,3 +92,180 @@ func BenchmarkParallelGet(b *testing.B) {
 		}
 	})
 }
+
+// BenchmarkSnapshot ölçümü için
+func BenchmarkSnapshot(b *testing.B) {
+	om := NewOrderedMap()
+	for i := 0; i < 1000; i++ {
+		om.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		om.Snapshot()
+	}
+}
+
+// BenchmarkSnapshotThenSet ilk yazmada kopyalama maliyeti için
+func BenchmarkSnapshotThenSet(b *testing.B) {
+	om := NewOrderedMap()
+	for i := 0; i < 1000; i++ {
+		om.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		om.Snapshot()
+		om.Set(i%1000, i)
+	}
+}
+
+// BenchmarkCopyThenSet Snapshot yerine Copy ile karşılaştırma için
+func BenchmarkCopyThenSet(b *testing.B) {
+	om := NewOrderedMap()
+	for i := 0; i < 1000; i++ {
+		om.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		om.Copy()
+		om.Set(i%1000, i)
+	}
+}
+
+// BenchmarkSetLoop1000 ölçümü için
+func BenchmarkSetLoop1000(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		om := NewOrderedMap()
+		for j := 0; j < 1000; j++ {
+			om.Set(j, j)
+		}
+	}
+}
+
+// BenchmarkSetMany1000 ölçümü için
+func BenchmarkSetMany1000(b *testing.B) {
+	pairs := make([]Pair, 1000)
+	for j := range pairs {
+		pairs[j] = Pair{j, j}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		om := NewOrderedMap()
+		om.SetMany(pairs...)
+	}
+}
+
+// BenchmarkGetMany ölçümü için
+func BenchmarkGetMany(b *testing.B) {
+	om := NewOrderedMap()
+	keys := make([]any, 1000)
+	for j := range keys {
+		om.Set(j, j)
+		keys[j] = j
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		om.GetMany(keys...)
+	}
+}
+
+// BenchmarkSetWithCapacity ölçümü için
+func BenchmarkSetWithCapacity(b *testing.B) {
+	om := New(WithCapacity(b.N))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		om.Set(i, i)
+	}
+}
+
+// BenchmarkSetWithoutLocking ölçümü için
+func BenchmarkSetWithoutLocking(b *testing.B) {
+	om := New(WithoutLocking())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		om.Set(i, i)
+	}
+}
+
+// BenchmarkGetWithoutLocking ölçümü için
+func BenchmarkGetWithoutLocking(b *testing.B) {
+	om := New(WithoutLocking())
+	for i := 0; i < 1000; i++ {
+		om.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		om.Get(i % 1000)
+	}
+}
+
+// BenchmarkLocalSet ölçümü için
+func BenchmarkLocalSet(b *testing.B) {
+	lm := NewLocal()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lm.Set(i, i)
+	}
+}
+
+// BenchmarkLocalGet ölçümü için
+func BenchmarkLocalGet(b *testing.B) {
+	lm := NewLocal()
+	for i := 0; i < 1000; i++ {
+		lm.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lm.Get(i % 1000)
+	}
+}
+
+// BenchmarkSortKeys ölçümü için
+func BenchmarkSortKeys(b *testing.B) {
+	om := NewOrderedMap()
+	for i := 0; i < 1000; i++ {
+		om.Set((i*7919)%1000, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		om.SortFunc(func(a, b Entry) int {
+			return b.Key.(int) - a.Key.(int)
+		})
+		om.SortKeys()
+	}
+}
+
+// BenchmarkSortedMapSet ölçümü için
+func BenchmarkSortedMapSet(b *testing.B) {
+	sm := NewSortedMap(nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sm.Set((i*7919)%100000, i)
+	}
+}
+
+// BenchmarkSortedMapGet ölçümü için
+func BenchmarkSortedMapGet(b *testing.B) {
+	sm := NewSortedMap(nil)
+	for i := 0; i < 1000; i++ {
+		sm.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sm.Get(i % 1000)
+	}
+}
+
+// BenchmarkParallelMap ölçümü için
+func BenchmarkParallelMap(b *testing.B) {
+	om := NewOrderedMap()
+	for i := 0; i < 1000; i++ {
+		om.Set(i, i)
+	}
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		om.ParallelMap(ctx, 0, func(ctx context.Context, key, value any) (any, any, error) {
+			return key, value.(int) * 2, nil
+		})
+	}
+}
@@ -1876,3 +1876,41 @@ func TestOrderedMap_RangeModifySameMap(t *testing.T) {
 		t.Fatal("Deadlock: Set inside Range hung")
 	}
 }
+
+func TestOrderedMap_MoveToFrontBack(t *testing.T) {
+	om := NewOrderedMap()
+	for _, k := range []string{"a", "b", "c", "d"} {
+		om.Set(k, k)
+	}
+
+	t.Run("Move Middle", func(t *testing.T) {
+		if !om.MoveToFront("c") {
+			t.Error("Expected MoveToFront to return true")
+		}
+		if !om.MoveToBack("a") {
+			t.Error("Expected MoveToBack to return true")
+		}
+		if om.String() != "{c: c, b: b, d: d, a: a}" {
+			t.Errorf("Unexpected order %s", om.String())
+		}
+	})
+
+	t.Run("Move Ends", func(t *testing.T) {
+		om.MoveToFront("c")
+		om.MoveToBack("a")
+		om.MoveToFront("a")
+		om.MoveToBack("c")
+		if om.String() != "{a: a, b: b, d: d, c: c}" {
+			t.Errorf("Unexpected order %s", om.String())
+		}
+		if k, _, _ := om.Last(); k != "c" {
+			t.Errorf("Expected tail c, got %v", k)
+		}
+	})
+
+	t.Run("Missing Key", func(t *testing.T) {
+		if om.MoveToFront("missing") || om.MoveToBack(nil) {
+			t.Error("Expected false for missing or nil key")
+		}
+	})
+}
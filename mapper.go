@@ -0,0 +1,104 @@
+package orderedmap
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Collision selects what MapErr does when the mapper returns a key that an
+// earlier element already produced.
+type Collision int
+
+const (
+	// KeepLast replaces the earlier value with the later one, as Map does.
+	KeepLast Collision = iota
+	// KeepFirst keeps the earlier value and ignores the later one.
+	KeepFirst
+	// CombineValues stores the result of MapOptions.Combine.
+	CombineValues
+	// FailOnCollision stops the mapping and returns a *MapError wrapping
+	// ErrKeyCollision.
+	FailOnCollision
+)
+
+// ErrKeyCollision is wrapped by the error MapErr returns when two elements map
+// to the same key under FailOnCollision.
+var ErrKeyCollision = errors.New("key collision")
+
+// MapOptions configures MapErr.
+type MapOptions struct {
+	// OnCollision is the policy for elements that map to an existing key.
+	// The default is KeepLast.
+	OnCollision Collision
+	// Combine merges the existing and the incoming value for a key. It is
+	// required when OnCollision is CombineValues.
+	Combine func(key, existing, incoming any) any
+}
+
+// MapError identifies the source element that made MapErr fail.
+type MapError struct {
+	SourceKey any   // Key of the element in the source map
+	Err       error // The underlying error
+}
+
+func (e *MapError) Error() string {
+	return fmt.Sprintf("map: source key %v: %v", e.SourceKey, e.Err)
+}
+
+func (e *MapError) Unwrap() error {
+	return e.Err
+}
+
+// MapErr creates a new OrderedMap by transforming each element with fn, like
+// Map, but lets fn fail and makes key collisions explicit. If two elements map
+// to the same key, opts.OnCollision decides which value is kept; the entry
+// keeps the position of the first element either way. If opts is nil, default
+// options are used.
+//
+// An error from fn, a nil key or a collision under FailOnCollision stops the
+// mapping and is returned as a *MapError carrying the source key, along with
+// a nil map. fn must not modify the map. This method is thread-safe.
+//
+// Example:
+//
+//	byEmail, err := users.MapErr(func(key, value any) (any, any, error) {
+//	    u := value.(User)
+//	    if u.Email == "" {
+//	        return nil, nil, fmt.Errorf("missing email")
+//	    }
+//	    return u.Email, u, nil
+//	}, &MapOptions{OnCollision: FailOnCollision})
+func (om *OrderedMap) MapErr(fn func(key, value any) (any, any, error), opts *MapOptions) (*OrderedMap, error) {
+	if opts == nil {
+		opts = &MapOptions{}
+	}
+	if opts.OnCollision == CombineValues && opts.Combine == nil {
+		return nil, fmt.Errorf("combine function cannot be nil")
+	}
+
+	om.rlock()
+	defer om.runlock()
+
+	mapped := om.derive(om.length)
+	for current := om.head; current != nil; current = current.next {
+		newKey, newValue, err := fn(current.Key, current.Value)
+		if err != nil {
+			return nil, &MapError{SourceKey: current.Key, Err: err}
+		}
+
+		if node, exists := mapped.lookup(newKey); exists {
+			switch opts.OnCollision {
+			case KeepFirst:
+				continue
+			case CombineValues:
+				newValue = opts.Combine(node.Key, node.Value, newValue)
+			case FailOnCollision:
+				return nil, &MapError{SourceKey: current.Key, Err: fmt.Errorf("%w on %v", ErrKeyCollision, newKey)}
+			}
+		}
+		if err := mapped.set(newKey, newValue); err != nil {
+			return nil, &MapError{SourceKey: current.Key, Err: err}
+		}
+	}
+	return mapped, nil
+}
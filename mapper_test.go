@@ -0,0 +1,95 @@
+package orderedmap
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOrderedMap_MapErr(t *testing.T) {
+	om := NewOrderedMap()
+	om.Set("apple", 1)
+	om.Set("avocado", 2)
+	om.Set("banana", 3)
+	om.Set("apricot", 4)
+
+	byInitial := func(key, value any) (any, any, error) {
+		return key.(string)[:1], value, nil
+	}
+
+	t.Run("Default Keeps Last", func(t *testing.T) {
+		mapped, err := om.MapErr(byInitial, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mapped.String() != "{a: 4, b: 3}" {
+			t.Errorf("Expected {a: 4, b: 3}, got %s", mapped.String())
+		}
+	})
+
+	t.Run("Keep First", func(t *testing.T) {
+		mapped, err := om.MapErr(byInitial, &MapOptions{OnCollision: KeepFirst})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mapped.String() != "{a: 1, b: 3}" {
+			t.Errorf("Expected {a: 1, b: 3}, got %s", mapped.String())
+		}
+	})
+
+	t.Run("Combine", func(t *testing.T) {
+		mapped, err := om.MapErr(byInitial, &MapOptions{
+			OnCollision: CombineValues,
+			Combine: func(key, existing, incoming any) any {
+				return existing.(int) + incoming.(int)
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mapped.String() != "{a: 7, b: 3}" {
+			t.Errorf("Expected {a: 7, b: 3}, got %s", mapped.String())
+		}
+
+		if _, err := om.MapErr(byInitial, &MapOptions{OnCollision: CombineValues}); err == nil {
+			t.Error("Expected error for missing combine function")
+		}
+	})
+
+	t.Run("Fail On Collision", func(t *testing.T) {
+		mapped, err := om.MapErr(byInitial, &MapOptions{OnCollision: FailOnCollision})
+		if !errors.Is(err, ErrKeyCollision) || mapped != nil {
+			t.Fatalf("Expected ErrKeyCollision, got %v", err)
+		}
+		var mapErr *MapError
+		if !errors.As(err, &mapErr) || mapErr.SourceKey != "avocado" {
+			t.Errorf("Expected source key avocado, got %v", err)
+		}
+	})
+
+	t.Run("Mapper Error", func(t *testing.T) {
+		errBad := errors.New("bad value")
+		_, err := om.MapErr(func(key, value any) (any, any, error) {
+			if value == 3 {
+				return nil, nil, errBad
+			}
+			return key, value, nil
+		}, nil)
+		var mapErr *MapError
+		if !errors.Is(err, errBad) || !errors.As(err, &mapErr) || mapErr.SourceKey != "banana" {
+			t.Errorf("Expected bad value for banana, got %v", err)
+		}
+	})
+
+	t.Run("Nil Key", func(t *testing.T) {
+		_, err := om.MapErr(func(key, value any) (any, any, error) {
+			if key == "apricot" {
+				return nil, value, nil
+			}
+			return key, value, nil
+		}, nil)
+		var mapErr *MapError
+		if !errors.As(err, &mapErr) || mapErr.SourceKey != "apricot" {
+			t.Errorf("Expected error for apricot, got %v", err)
+		}
+	})
+}
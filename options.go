@@ -0,0 +1,163 @@
+package orderedmap
+
+import "strings"
+
+// Option configures an OrderedMap created with New.
+type Option func(*OrderedMap)
+
+// WithCapacity pre-sizes the map for about n entries, avoiding rehashing
+// while it is filled.
+//
+// Example:
+//
+//	om := New(WithCapacity(10000))
+func WithCapacity(n int) Option {
+	return func(om *OrderedMap) {
+		if n > 0 {
+			om.nodeMap = make(map[any]*Node, n)
+		}
+	}
+}
+
+// WithoutLocking disables the map's internal read-write mutex. The map must
+// then only be used from one goroutine at a time, and features that need a
+// second goroutine, such as WaitFor or context-bound subscriptions, must not
+// be used.
+//
+// Example:
+//
+//	om := New(WithoutLocking())
+func WithoutLocking() Option {
+	return func(om *OrderedMap) {
+		om.nolock = true
+	}
+}
+
+// WithMaxLen bounds the map to n entries. Inserting a new key into a full map
+// evicts the front (oldest) entry, which is reported as an EventDeleted.
+// Values of existing keys can always be updated. n <= 0 means no limit.
+//
+// Example:
+//
+//	recent := New(WithMaxLen(100))
+func WithMaxLen(n int) Option {
+	return func(om *OrderedMap) {
+		if n > 0 {
+			om.maxLen = n
+		}
+	}
+}
+
+// WithKeyNormalizer makes the map pass every key through fn before storing or
+// looking it up, so keys that normalize to the same value are treated as one
+// key. The normalized key is the one stored and returned by Keys. fn must
+// return a comparable value and must not return nil for a non-nil key.
+// Internally fn is applied once per key passed in, but keys returned by Keys
+// or Range are passed through fn again when handed back to the map, so fn
+// should normally be idempotent.
+//
+// Example:
+//
+//	headers := New(WithKeyNormalizer(func(key any) any {
+//	    if s, ok := key.(string); ok {
+//	        return strings.ToLower(s)
+//	    }
+//	    return key
+//	}))
+func WithKeyNormalizer(fn func(key any) any) Option {
+	return func(om *OrderedMap) {
+		om.normalize = fn
+	}
+}
+
+// New creates an OrderedMap configured by opts. Maps derived from the
+// returned map, such as those returned by Copy, Clone, Reverse, Filter, Map
+// and the set operations, keep its options other than the capacity.
+//
+// Example:
+//
+//	om := New(WithCapacity(1000), WithMaxLen(1000))
+func New(opts ...Option) *OrderedMap {
+	om := &OrderedMap{}
+	for _, opt := range opts {
+		opt(om)
+	}
+	if om.nodeMap == nil {
+		om.nodeMap = make(map[any]*Node)
+	}
+	return om
+}
+
+// derive returns an empty map with the options of om, for results built
+// from om's entries.
+func (om *OrderedMap) derive(capacity int) *OrderedMap {
+	return &OrderedMap{
+		nodeMap:   make(map[any]*Node, capacity),
+		nolock:    om.nolock,
+		maxLen:    om.maxLen,
+		normalize: om.normalize,
+	}
+}
+
+func (om *OrderedMap) lock() {
+	if !om.nolock {
+		om.mu.Lock()
+	}
+}
+
+func (om *OrderedMap) unlock() {
+	stalled := om.stalled
+	om.stalled = nil
+	if !om.nolock {
+		om.mu.Unlock()
+	}
+	if stalled != nil {
+		waitStalled(stalled)
+	}
+}
+
+func (om *OrderedMap) rlock() {
+	if !om.nolock {
+		om.mu.RLock()
+	}
+}
+
+func (om *OrderedMap) runlock() {
+	if !om.nolock {
+		om.mu.RUnlock()
+	}
+}
+
+// normalizeKey applies the key normalizer, if any.
+func (om *OrderedMap) normalizeKey(key any) any {
+	if om.normalize == nil || key == nil {
+		return key
+	}
+	return om.normalize(key)
+}
+
+// lowerKey is a key normalizer that folds string keys to lower case, as used
+// by case-insensitive INI maps.
+func lowerKey(key any) any {
+	if s, ok := key.(string); ok {
+		return strings.ToLower(s)
+	}
+	return key
+}
+
+// lookup returns the node stored for key. The caller must hold om.mu.
+func (om *OrderedMap) lookup(key any) (*Node, bool) {
+	if key == nil {
+		return nil, false
+	}
+	node, exists := om.nodeMap[om.normalizeKey(key)]
+	return node, exists
+}
+
+// evict removes entries from the front while the map is over its maximum
+// length. The caller must hold om.mu for writing.
+func (om *OrderedMap) evict() {
+	for om.maxLen > 0 && om.length > om.maxLen {
+		om.remove(om.head)
+	}
+}
@@ -0,0 +1,225 @@
+package orderedmap
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		om := New()
+		om.Set("a", 1)
+		if om.Len() != 1 || om.nolock || om.maxLen != 0 || om.normalize != nil {
+			t.Error("Expected New() to behave like NewOrderedMap()")
+		}
+	})
+
+	t.Run("WithCapacity", func(t *testing.T) {
+		om := New(WithCapacity(100), WithCapacity(-1))
+		for i := 0; i < 100; i++ {
+			om.Set(i, i)
+		}
+		if om.Len() != 100 {
+			t.Errorf("Expected 100 elements, got %d", om.Len())
+		}
+	})
+
+	t.Run("WithoutLocking", func(t *testing.T) {
+		om := New(WithoutLocking())
+		om.Set("a", 1)
+		om.Set("b", 2)
+		om.MoveToFront("b")
+		om.Update(func(tx *Tx) error {
+			tx.Delete("a")
+			return nil
+		})
+		snap := om.Snapshot()
+		om.Set("c", 3)
+		if om.String() != "{b: 2, c: 3}" || snap.String() != "{b: 2}" {
+			t.Errorf("Unexpected state %s / %s", om.String(), snap.String())
+		}
+	})
+}
+
+func TestNew_WithMaxLen(t *testing.T) {
+	om := New(WithMaxLen(3))
+	var evicted []any
+	om.OnDelete(func(ev Event) { evicted = append(evicted, ev.Key) })
+
+	for i := 0; i < 5; i++ {
+		om.Set(i, i)
+	}
+	if !reflect.DeepEqual(om.Keys(), []any{2, 3, 4}) {
+		t.Errorf("Expected the oldest entries to be evicted, got %v", om.Keys())
+	}
+	if !reflect.DeepEqual(evicted, []any{0, 1}) {
+		t.Errorf("Expected Deleted events for 0 and 1, got %v", evicted)
+	}
+
+	// Updates never evict.
+	om.Set(2, "updated")
+	if om.Len() != 3 || !om.Has(2) {
+		t.Error("Expected update not to evict")
+	}
+
+	// Moving an entry to the back protects it from the next eviction.
+	om.MoveToBack(2)
+	om.Set(5, 5)
+	if !reflect.DeepEqual(om.Keys(), []any{4, 2, 5}) {
+		t.Errorf("Unexpected keys %v", om.Keys())
+	}
+
+	t.Run("Rollback Restores Evicted", func(t *testing.T) {
+		before := om.String()
+		om.Update(func(tx *Tx) error {
+			tx.Set(6, 6)
+			tx.Set(7, 7)
+			return errors.New("rollback")
+		})
+		if om.String() != before {
+			t.Errorf("Expected %s, got %s", before, om.String())
+		}
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		bounded := New(WithMaxLen(2))
+		bounded.SetMany(Pair{"a", 1}, Pair{"b", 2}, Pair{"c", 3})
+		if !reflect.DeepEqual(bounded.Keys(), []any{"b", "c"}) {
+			t.Errorf("Unexpected keys %v", bounded.Keys())
+		}
+	})
+}
+
+func TestNew_WithKeyNormalizer(t *testing.T) {
+	om := New(WithKeyNormalizer(lowerKey))
+	om.Set("Content-Type", "text/plain")
+	om.Set("CONTENT-TYPE", "application/json")
+	om.Set("Accept", "*/*")
+
+	if om.Len() != 2 {
+		t.Errorf("Expected 2 elements, got %d", om.Len())
+	}
+	if v, ok := om.Get("content-TYPE"); !ok || v != "application/json" {
+		t.Errorf("Expected normalized lookup, got %v", v)
+	}
+	if !om.Has("ACCEPT") || !reflect.DeepEqual(om.Keys(), []any{"content-type", "accept"}) {
+		t.Errorf("Unexpected keys %v", om.Keys())
+	}
+	if !om.MoveToFront("ACCEPT") {
+		t.Error("Expected MoveToFront with a differently cased key to succeed")
+	}
+	if r := om.GetMany("Accept"); !r[0].Found {
+		t.Error("Expected GetMany to normalize keys")
+	}
+
+	snap := om.Snapshot()
+	if !snap.Has("Content-Type") {
+		t.Error("Expected snapshot lookups to normalize keys")
+	}
+
+	om.Update(func(tx *Tx) error {
+		tx.Set("ACCEPT", "text/html")
+		if v, _ := tx.Get("accept"); v != "text/html" {
+			t.Errorf("Expected tx to normalize keys, got %v", v)
+		}
+		tx.Delete("Content-type")
+		return nil
+	})
+	if om.String() != "{accept: text/html}" {
+		t.Errorf("Unexpected state %s", om.String())
+	}
+
+	om.Delete("Accept")
+	if om.Len() != 0 {
+		t.Error("Expected Delete to normalize keys")
+	}
+
+	t.Run("ReadSnapshot", func(t *testing.T) {
+		src := NewOrderedMap()
+		src.Set("A", 1)
+		src.Set("a", 2)
+		var buf bytes.Buffer
+		if err := src.WriteSnapshot(&buf); err != nil {
+			t.Fatal(err)
+		}
+		dst := New(WithKeyNormalizer(lowerKey))
+		if err := dst.ReadSnapshot(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if dst.String() != "{a: 2}" {
+			t.Errorf("Expected keys to be normalized on restore, got %s", dst.String())
+		}
+	})
+
+	t.Run("Not Idempotent", func(t *testing.T) {
+		prefix := func(key any) any { return "x" + key.(string) }
+		om := New(WithKeyNormalizer(prefix))
+
+		errAbort := errors.New("abort")
+		err := om.Update(func(tx *Tx) error {
+			tx.Set("a", 1)
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) || om.Len() != 0 {
+			t.Fatalf("Expected rollback to leave the map empty, got %v, %s", err, om.String())
+		}
+
+		om.Update(func(tx *Tx) error {
+			tx.Set("a", 1)
+			return tx.Set("a", 2)
+		})
+		if om.String() != "{xa: 2}" {
+			t.Errorf("Expected {xa: 2}, got %s", om.String())
+		}
+
+		src := NewOrderedMap()
+		src.Set("b", 3)
+		var buf bytes.Buffer
+		src.WriteSnapshot(&buf)
+		if err := om.ReadSnapshot(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if om.String() != "{xb: 3}" {
+			t.Errorf("Expected {xb: 3}, got %s", om.String())
+		}
+	})
+
+	t.Run("Nil Result", func(t *testing.T) {
+		bad := New(WithKeyNormalizer(func(any) any { return nil }))
+		if err := bad.Set("x", 1); err == nil {
+			t.Error("Expected error when the normalizer returns nil")
+		}
+	})
+}
+
+func TestNew_DerivedMapsKeepOptions(t *testing.T) {
+	om := New(WithKeyNormalizer(lowerKey), WithMaxLen(3))
+	om.Set("A", 1)
+	om.Set("B", 2)
+	other := New(WithKeyNormalizer(lowerKey))
+	other.Set("c", 3)
+
+	derived := map[string]*OrderedMap{
+		"Copy":       om.Copy(),
+		"Clone":      om.Clone(),
+		"Reverse":    om.Reverse(),
+		"Filter":     om.Filter(func(key, value any) bool { return true }),
+		"Map":        om.Map(func(key, value any) (any, any) { return key, value }),
+		"Union":      Union(om, other, nil),
+		"Difference": Difference(om, other),
+	}
+	for name, m := range derived {
+		if !m.Has("a") {
+			t.Errorf("%s: expected a case-insensitive lookup to find a", name)
+		}
+		m.Set("X", 0)
+		m.Set("x", 0)
+		m.Set("Y", 0)
+		m.Set("Z", 0)
+		if m.Len() != 3 || !m.Has("z") {
+			t.Errorf("%s: expected the length limit and normalizer to apply, got %s", name, m.String())
+		}
+	}
+}
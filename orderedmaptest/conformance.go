@@ -0,0 +1,236 @@
+// Package orderedmaptest provides a conformance test suite for
+// implementations of orderedmap.Map.
+//
+// Example:
+//
+//	func TestMyMap(t *testing.T) {
+//	    orderedmaptest.RunConformance(t, func() orderedmap.Map {
+//	        return NewMyMap()
+//	    })
+//	}
+package orderedmaptest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mstgnz/orderedmap"
+)
+
+// RunConformance runs the conformance suite against maps created by factory.
+// factory must return a new, empty map on every call.
+//
+// The suite only inserts keys in ascending order, so maps that order their
+// entries by key pass as well as maps that keep insertion order.
+func RunConformance(t *testing.T, factory func() orderedmap.Map) {
+	t.Helper()
+
+	t.Run("Empty", func(t *testing.T) {
+		m := factory()
+		if m.Len() != 0 {
+			t.Errorf("Expected length 0, got %d", m.Len())
+		}
+		if len(m.Keys()) != 0 || len(m.Values()) != 0 {
+			t.Errorf("Expected no keys or values, got %v and %v", m.Keys(), m.Values())
+		}
+		if _, ok := m.Get(key(0)); ok {
+			t.Error("Expected Get on empty map to fail")
+		}
+		if m.Has(key(0)) {
+			t.Error("Expected Has on empty map to be false")
+		}
+		if _, _, ok := m.First(); ok {
+			t.Error("Expected First on empty map to fail")
+		}
+		if _, _, ok := m.Last(); ok {
+			t.Error("Expected Last on empty map to fail")
+		}
+		m.Range(func(key, value any) bool {
+			t.Errorf("Unexpected entry %v in empty map", key)
+			return true
+		})
+	})
+
+	t.Run("Nil Key", func(t *testing.T) {
+		m := factory()
+		if err := m.Set(nil, 1); err == nil {
+			t.Error("Expected error when setting a nil key")
+		}
+		if err := m.Delete(nil); err == nil {
+			t.Error("Expected error when deleting a nil key")
+		}
+		if _, ok := m.Get(nil); ok {
+			t.Error("Expected Get(nil) to fail")
+		}
+		if m.Has(nil) {
+			t.Error("Expected Has(nil) to be false")
+		}
+		if m.Len() != 0 {
+			t.Errorf("Expected length 0, got %d", m.Len())
+		}
+	})
+
+	t.Run("Set And Get", func(t *testing.T) {
+		m := filled(t, factory, 10)
+		if m.Len() != 10 {
+			t.Errorf("Expected length 10, got %d", m.Len())
+		}
+		for i := 0; i < 10; i++ {
+			if v, ok := m.Get(key(i)); !ok || v != i {
+				t.Errorf("Expected %s=%d, got %v, %v", key(i), i, v, ok)
+			}
+			if !m.Has(key(i)) {
+				t.Errorf("Expected Has(%s)", key(i))
+			}
+		}
+		expectOrder(t, m, keys(0, 10), values(0, 10))
+	})
+
+	t.Run("Update Keeps Position", func(t *testing.T) {
+		m := filled(t, factory, 5)
+		if err := m.Set(key(2), "updated"); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		if m.Len() != 5 {
+			t.Errorf("Expected length 5, got %d", m.Len())
+		}
+		if v, _ := m.Get(key(2)); v != "updated" {
+			t.Errorf("Expected updated value, got %v", v)
+		}
+		want := values(0, 5)
+		want[2] = "updated"
+		expectOrder(t, m, keys(0, 5), want)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		m := filled(t, factory, 5)
+		if err := m.Delete(key(2)); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if err := m.Delete(key(99)); err != nil {
+			t.Errorf("Expected deleting a missing key to succeed, got %v", err)
+		}
+		if m.Len() != 4 || m.Has(key(2)) {
+			t.Errorf("Expected %s to be deleted", key(2))
+		}
+		if _, ok := m.Get(key(2)); ok {
+			t.Errorf("Expected Get(%s) to fail after delete", key(2))
+		}
+		expectOrder(t, m, []any{key(0), key(1), key(3), key(4)}, []any{0, 1, 3, 4})
+
+		m.Delete(key(0))
+		m.Delete(key(4))
+		if k, _, _ := m.First(); k != key(1) {
+			t.Errorf("Expected first %s after deleting the head, got %v", key(1), k)
+		}
+		if k, _, _ := m.Last(); k != key(3) {
+			t.Errorf("Expected last %s after deleting the tail, got %v", key(3), k)
+		}
+
+		m.Set(key(9), 9)
+		expectOrder(t, m, []any{key(1), key(3), key(9)}, []any{1, 3, 9})
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		m := filled(t, factory, 5)
+		var visited []any
+		m.Range(func(key, value any) bool {
+			visited = append(visited, key)
+			return len(visited) < 3
+		})
+		if !reflect.DeepEqual(visited, keys(0, 3)) {
+			t.Errorf("Expected Range to stop after 3 entries, got %v", visited)
+		}
+	})
+
+	t.Run("Returned Slices Are Copies", func(t *testing.T) {
+		m := filled(t, factory, 3)
+		ks := m.Keys()
+		vs := m.Values()
+		ks[0] = "changed"
+		vs[0] = "changed"
+		expectOrder(t, m, keys(0, 3), values(0, 3))
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		m := filled(t, factory, 5)
+		m.Clear()
+		if m.Len() != 0 || m.Has(key(0)) || len(m.Keys()) != 0 {
+			t.Error("Expected map to be empty after Clear")
+		}
+		if _, _, ok := m.First(); ok {
+			t.Error("Expected First to fail after Clear")
+		}
+		m.Set(key(7), 7)
+		expectOrder(t, m, []any{key(7)}, []any{7})
+	})
+}
+
+// key returns the i-th test key. Keys sort in the same order as i.
+func key(i int) string {
+	return fmt.Sprintf("key%03d", i)
+}
+
+func keys(from, to int) []any {
+	ks := make([]any, 0, to-from)
+	for i := from; i < to; i++ {
+		ks = append(ks, key(i))
+	}
+	return ks
+}
+
+func values(from, to int) []any {
+	vs := make([]any, 0, to-from)
+	for i := from; i < to; i++ {
+		vs = append(vs, i)
+	}
+	return vs
+}
+
+// filled returns a map holding key(i) = i for i in [0, n).
+func filled(t *testing.T, factory func() orderedmap.Map, n int) orderedmap.Map {
+	t.Helper()
+	m := factory()
+	for i := 0; i < n; i++ {
+		if err := m.Set(key(i), i); err != nil {
+			t.Fatalf("Set(%s) failed: %v", key(i), err)
+		}
+	}
+	return m
+}
+
+// expectOrder checks that all ways of reading m agree on the given order.
+func expectOrder(t *testing.T, m orderedmap.ReadableMap, wantKeys, wantValues []any) {
+	t.Helper()
+	if !reflect.DeepEqual(m.Keys(), wantKeys) {
+		t.Errorf("Expected keys %v, got %v", wantKeys, m.Keys())
+	}
+	if !reflect.DeepEqual(m.Values(), wantValues) {
+		t.Errorf("Expected values %v, got %v", wantValues, m.Values())
+	}
+
+	var rangeKeys, rangeValues []any
+	m.Range(func(key, value any) bool {
+		rangeKeys = append(rangeKeys, key)
+		rangeValues = append(rangeValues, value)
+		return true
+	})
+	if !reflect.DeepEqual(rangeKeys, wantKeys) || !reflect.DeepEqual(rangeValues, wantValues) {
+		t.Errorf("Expected Range to visit %v, got %v", wantKeys, rangeKeys)
+	}
+
+	if len(wantKeys) == 0 {
+		return
+	}
+	if k, v, ok := m.First(); !ok || k != wantKeys[0] || v != wantValues[0] {
+		t.Errorf("Expected first (%v, %v), got (%v, %v)", wantKeys[0], wantValues[0], k, v)
+	}
+	last := len(wantKeys) - 1
+	if k, v, ok := m.Last(); !ok || k != wantKeys[last] || v != wantValues[last] {
+		t.Errorf("Expected last (%v, %v), got (%v, %v)", wantKeys[last], wantValues[last], k, v)
+	}
+	if m.Len() != len(wantKeys) {
+		t.Errorf("Expected length %d, got %d", len(wantKeys), m.Len())
+	}
+}
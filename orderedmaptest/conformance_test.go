@@ -0,0 +1,24 @@
+package orderedmaptest
+
+import (
+	"testing"
+
+	"github.com/mstgnz/orderedmap"
+)
+
+func TestConformance(t *testing.T) {
+	t.Run("OrderedMap", func(t *testing.T) {
+		RunConformance(t, func() orderedmap.Map { return orderedmap.NewOrderedMap() })
+	})
+	t.Run("OrderedMap With Options", func(t *testing.T) {
+		RunConformance(t, func() orderedmap.Map {
+			return orderedmap.New(orderedmap.WithCapacity(16), orderedmap.WithoutLocking())
+		})
+	})
+	t.Run("LocalMap", func(t *testing.T) {
+		RunConformance(t, func() orderedmap.Map { return orderedmap.NewLocal() })
+	})
+	t.Run("SortedMap", func(t *testing.T) {
+		RunConformance(t, func() orderedmap.Map { return orderedmap.NewSortedMap(nil) })
+	})
+}
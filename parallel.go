@@ -0,0 +1,150 @@
+package orderedmap
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ParallelMap creates a new OrderedMap by transforming each element with fn,
+// running up to workers calls of fn concurrently. If workers <= 0,
+// runtime.GOMAXPROCS(0) workers are used.
+//
+// The elements are copied under the read lock first, so writers are not
+// blocked while fn runs and fn may use the map. The result is assembled in
+// the original order; if two elements map to the same key, the later one
+// wins, as in Map.
+//
+// The first error returned by fn, or a nil key, cancels the context passed to
+// the remaining calls and is returned along with a nil map. If ctx is done
+// first, ctx.Err() is returned. This method is thread-safe.
+//
+// Example:
+//
+//	rendered, err := pages.ParallelMap(ctx, 8, func(ctx context.Context, key, value any) (any, any, error) {
+//	    html, err := render(ctx, value.(Page))
+//	    return key, html, err
+//	})
+func (om *OrderedMap) ParallelMap(ctx context.Context, workers int, fn func(ctx context.Context, key, value any) (newKey, newValue any, err error)) (*OrderedMap, error) {
+	entries := om.entries()
+	results := make([]Pair, len(entries))
+
+	err := runParallel(ctx, workers, len(entries), func(ctx context.Context, i int) error {
+		key, value, err := fn(ctx, entries[i].Key, entries[i].Value)
+		if err != nil {
+			return err
+		}
+		if key == nil {
+			return fmt.Errorf("mapper returned a nil key for key %v", entries[i].Key)
+		}
+		results[i] = Pair{key, value}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mapped := om.derive(len(results))
+	for _, p := range results {
+		_ = mapped.set(p.Key, p.Value)
+	}
+	return mapped, nil
+}
+
+// ParallelFilter returns a new OrderedMap with the elements for which
+// predicate returns true, running up to workers calls of predicate
+// concurrently. It follows the same rules as ParallelMap for workers,
+// locking, ordering, errors and cancellation. This method is thread-safe.
+//
+// Example:
+//
+//	reachable, err := hosts.ParallelFilter(ctx, 16, func(ctx context.Context, key, value any) (bool, error) {
+//	    return ping(ctx, value.(string)) == nil, nil
+//	})
+func (om *OrderedMap) ParallelFilter(ctx context.Context, workers int, predicate func(ctx context.Context, key, value any) (bool, error)) (*OrderedMap, error) {
+	entries := om.entries()
+	keep := make([]bool, len(entries))
+
+	err := runParallel(ctx, workers, len(entries), func(ctx context.Context, i int) error {
+		ok, err := predicate(ctx, entries[i].Key, entries[i].Value)
+		keep[i] = ok
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := om.derive(0)
+	for i, e := range entries {
+		if keep[i] {
+			_ = filtered.setNormalized(e.Key, e.Value)
+		}
+	}
+	return filtered, nil
+}
+
+// entries returns a copy of the map's elements in order.
+func (om *OrderedMap) entries() []Pair {
+	om.rlock()
+	defer om.runlock()
+
+	entries := make([]Pair, 0, om.length)
+	for current := om.head; current != nil; current = current.next {
+		entries = append(entries, Pair{current.Key, current.Value})
+	}
+	return entries
+}
+
+// runParallel calls task for every index in [0, n) on at most workers
+// goroutines. The first error cancels the context passed to the other tasks
+// and is returned; tasks not yet started are skipped.
+func runParallel(ctx context.Context, workers, n int, task func(ctx context.Context, i int) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		next     atomic.Int64
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := int(next.Add(1) - 1)
+				if i >= n {
+					return
+				}
+				if err := task(ctx, i); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	// Report cancellation of the caller's context even if every task that
+	// started finished cleanly.
+	return ctx.Err()
+}
@@ -0,0 +1,151 @@
+package orderedmap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOrderedMap_ParallelMap(t *testing.T) {
+	om := NewOrderedMap()
+	for i := 0; i < 100; i++ {
+		om.Set(i, i)
+	}
+
+	t.Run("Order Preserved", func(t *testing.T) {
+		mapped, err := om.ParallelMap(context.Background(), 8, func(ctx context.Context, key, value any) (any, any, error) {
+			// Finish out of order.
+			time.Sleep(time.Duration(100-key.(int)) * time.Microsecond)
+			return fmt.Sprint("k", key), value.(int) * 2, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mapped.Len() != 100 {
+			t.Fatalf("Expected 100 elements, got %d", mapped.Len())
+		}
+		i := 0
+		mapped.Range(func(key, value any) bool {
+			if key != fmt.Sprint("k", i) || value != i*2 {
+				t.Errorf("Unexpected entry %v=%v at %d", key, value, i)
+				return false
+			}
+			i++
+			return true
+		})
+	})
+
+	t.Run("Bounded Workers", func(t *testing.T) {
+		var running, peak atomic.Int32
+		_, err := om.ParallelMap(context.Background(), 3, func(ctx context.Context, key, value any) (any, any, error) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(100 * time.Microsecond)
+			running.Add(-1)
+			return key, value, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if peak.Load() > 3 {
+			t.Errorf("Expected at most 3 concurrent calls, got %d", peak.Load())
+		}
+	})
+
+	t.Run("First Error Cancels", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		var calls atomic.Int32
+		mapped, err := om.ParallelMap(context.Background(), 2, func(ctx context.Context, key, value any) (any, any, error) {
+			calls.Add(1)
+			if key == 5 {
+				return nil, nil, errBoom
+			}
+			return key, value, nil
+		})
+		if !errors.Is(err, errBoom) || mapped != nil {
+			t.Errorf("Expected boom and nil map, got %v", err)
+		}
+		if calls.Load() == 100 {
+			t.Error("Expected remaining calls to be skipped after the error")
+		}
+	})
+
+	t.Run("Nil Key", func(t *testing.T) {
+		_, err := om.ParallelMap(context.Background(), 4, func(ctx context.Context, key, value any) (any, any, error) {
+			if key == 7 {
+				return nil, value, nil
+			}
+			return key, value, nil
+		})
+		if err == nil {
+			t.Error("Expected error for a nil key")
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := om.ParallelMap(ctx, 4, nil); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected Canceled, got %v", err)
+		}
+	})
+
+	t.Run("Writers Not Blocked", func(t *testing.T) {
+		release := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			om.ParallelMap(context.Background(), 1, func(ctx context.Context, key, value any) (any, any, error) {
+				<-release
+				return key, value, nil
+			})
+		}()
+		time.Sleep(5 * time.Millisecond)
+		om.Set("writer", true) // Would deadlock if the read lock were held.
+		close(release)
+		<-done
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		mapped, err := NewOrderedMap().ParallelMap(context.Background(), 0, nil)
+		if err != nil || mapped.Len() != 0 {
+			t.Errorf("Expected empty result, got %v, %v", mapped, err)
+		}
+	})
+}
+
+func TestOrderedMap_ParallelFilter(t *testing.T) {
+	om := NewOrderedMap()
+	for i := 0; i < 50; i++ {
+		om.Set(i, i)
+	}
+
+	filtered, err := om.ParallelFilter(context.Background(), 0, func(ctx context.Context, key, value any) (bool, error) {
+		return value.(int)%5 == 0, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filtered.String() != "{0: 0, 5: 5, 10: 10, 15: 15, 20: 20, 25: 25, 30: 30, 35: 35, 40: 40, 45: 45}" {
+		t.Errorf("Unexpected result %s", filtered.String())
+	}
+
+	errBad := errors.New("bad")
+	_, err = om.ParallelFilter(context.Background(), 4, func(ctx context.Context, key, value any) (bool, error) {
+		if key == 30 {
+			return false, errBad
+		}
+		return true, ctx.Err()
+	})
+	if !errors.Is(err, errBad) {
+		t.Errorf("Expected bad, got %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package orderedmap
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ParseProperties reads a Java .properties file into a new OrderedMap of
+// string values, in file order, following the rules of
+// java.util.Properties.load:
+//
+//   - lines whose first non-blank character is "#" or "!" are comments,
+//   - a line ending in an odd number of backslashes continues on the next
+//     line, whose leading whitespace is ignored,
+//   - the key ends at the first unescaped "=", ":" or whitespace, and the
+//     separator may be surrounded by whitespace,
+//   - \t, \n, \r, \f and \uXXXX are unescaped in keys and values, and a
+//     backslash before any other character is dropped.
+//
+// The input is read as UTF-8. If a key appears more than once, the last
+// value wins and the key keeps its first position.
+//
+// The returned map remembers the comments, blank lines and original text of
+// the file, so WriteProperties reproduces it except where entries were
+// changed.
+//
+// Example:
+//
+//	f, err := os.Open("application.properties")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer f.Close()
+//	props, err := ParseProperties(f)
+func ParseProperties(r io.Reader) (*OrderedMap, error) {
+	lines, err := splitLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	om := NewOrderedMap()
+	layout := newTextLayout("properties")
+	var pending []string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimLeft(line, " \t\f")
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+			pending = append(pending, line)
+			continue
+		}
+
+		start := i
+		e := &layoutEntry{raw: []string{line}}
+		logical := trimmed
+		for continuesLine(logical) {
+			logical = logical[:len(logical)-1]
+			if i+1 == len(lines) {
+				break
+			}
+			i++
+			e.raw = append(e.raw, lines[i])
+			logical += strings.TrimLeft(lines[i], " \t\f")
+		}
+
+		keyEnd := 0
+		for keyEnd < len(logical) {
+			c := logical[keyEnd]
+			if c == '\\' {
+				keyEnd += 2
+				continue
+			}
+			if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+				break
+			}
+			keyEnd++
+		}
+		keyEnd = min(keyEnd, len(logical))
+		valueStart := keyEnd
+		for valueStart < len(logical) && strings.IndexByte(" \t\f", logical[valueStart]) >= 0 {
+			valueStart++
+		}
+		if valueStart < len(logical) && (logical[valueStart] == '=' || logical[valueStart] == ':') {
+			valueStart++
+			for valueStart < len(logical) && strings.IndexByte(" \t\f", logical[valueStart]) >= 0 {
+				valueStart++
+			}
+		}
+
+		key, err := unescapeProperties(logical[:keyEnd])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", start+1, err)
+		}
+		value, err := unescapeProperties(logical[valueStart:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", start+1, err)
+		}
+		e.head = line[:len(line)-len(trimmed)] + logical[:valueStart]
+		e.value = value
+		e.before = pending
+		pending = nil
+		layout.add(key, e)
+		_ = om.set(key, value)
+	}
+	layout.trailer = pending
+	om.layout = layout
+	return om, nil
+}
+
+// continuesLine reports whether line ends in an odd number of backslashes.
+func continuesLine(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+func unescapeProperties(s string) (string, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			r, err := parseUnicodeEscape(s, i+1)
+			if err != nil {
+				return "", err
+			}
+			i += 4
+			// Characters outside the BMP are written as a surrogate pair.
+			if utf16.IsSurrogate(r) && i+6 < len(s) && s[i+1] == '\\' && s[i+2] == 'u' {
+				if low, err := parseUnicodeEscape(s, i+3); err == nil {
+					if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+						r = pair
+						i += 6
+					}
+				}
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String(), nil
+}
+
+// parseUnicodeEscape parses the four hex digits of a \uXXXX escape at s[i:].
+func parseUnicodeEscape(s string, i int) (rune, error) {
+	if i+4 > len(s) {
+		return 0, fmt.Errorf("malformed \\uxxxx escape")
+	}
+	n, err := strconv.ParseUint(s[i:i+4], 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("malformed \\uxxxx escape %q", s[i-2:i+4])
+	}
+	return rune(n), nil
+}
+
+// WriteProperties writes the map to w in .properties format, one key=value
+// line per entry in map order. Keys and values are formatted with fmt.Sprint
+// and escaped like java.util.Properties.store does, except that non-ASCII
+// characters are written as UTF-8.
+//
+// If the map was returned by ParseProperties, its comments and blank lines
+// are written back in place, and unchanged entries keep their original text,
+// including line continuations, so changing one value changes one entry.
+// Deleted keys are left out along with the comments above them, and new keys
+// are added at their position in the map. This method is thread-safe.
+//
+// Example:
+//
+//	props.Set("server.port", "8080")
+//	if err := props.WriteProperties(f); err != nil {
+//	    log.Fatal(err)
+//	}
+func (om *OrderedMap) WriteProperties(w io.Writer) error {
+	return om.writeText(w, "properties", func(e *layoutEntry, key, value any) (string, error) {
+		escaped := escapeProperties(textValue(value), false)
+		if e == nil {
+			return escapeProperties(textValue(key), true) + "=" + escaped, nil
+		}
+		return e.head + escaped, nil
+	})
+}
+
+// escapeProperties escapes a key or value for a .properties file. In values
+// only a leading space needs escaping.
+func escapeProperties(s string, isKey bool) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch r {
+		case ' ':
+			if i == 0 || isKey {
+				sb.WriteByte('\\')
+			}
+			sb.WriteByte(' ')
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '=', ':', '#', '!', '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&sb, `\u%04X`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,136 @@
+package orderedmap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseProperties(t *testing.T) {
+	t.Run("Values", func(t *testing.T) {
+		input := "# comment\n" +
+			"! also a comment\n" +
+			"a=1\n" +
+			"b : 2\n" +
+			"c 3\n" +
+			"   d=indented\n" +
+			"key\\ with\\ spaces=v\n" +
+			"escaped\\=key=x\\ty\n" +
+			"unicode=caf\\u00e9 \\uD83D\\uDE00\n" +
+			"multi=one, \\\n" +
+			"      two, \\\n" +
+			"      three\n" +
+			"empty\n" +
+			"path=C:\\\\temp\n"
+		props, err := ParseProperties(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []Pair{
+			{"a", "1"},
+			{"b", "2"},
+			{"c", "3"},
+			{"d", "indented"},
+			{"key with spaces", "v"},
+			{"escaped=key", "x\ty"},
+			{"unicode", "café 😀"},
+			{"multi", "one, two, three"},
+			{"empty", ""},
+			{"path", `C:\temp`},
+		}
+		if props.Len() != len(expected) {
+			t.Fatalf("Expected %d entries, got %d: %s", len(expected), props.Len(), props.String())
+		}
+		for i, key := range props.Keys() {
+			value, _ := props.Get(key)
+			if key != expected[i].Key || value != expected[i].Value {
+				t.Errorf("Expected %v=%q, got %v=%q", expected[i].Key, expected[i].Value, key, value)
+			}
+		}
+	})
+
+	t.Run("Malformed Unicode", func(t *testing.T) {
+		if _, err := ParseProperties(strings.NewReader("a=\\u12\n")); err == nil {
+			t.Error("Expected error for a malformed escape")
+		}
+	})
+}
+
+func TestOrderedMap_WriteProperties(t *testing.T) {
+	input := "# App\n" +
+		"app.name = Demo\n" +
+		"app.list = a, \\\n" +
+		"           b\n" +
+		"\n" +
+		"! server\n" +
+		"server.port: 80\n" +
+		"server.host=old\n" +
+		"# end\n"
+
+	t.Run("Round Trip", func(t *testing.T) {
+		props, err := ParseProperties(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := props.WriteProperties(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != input {
+			t.Errorf("Expected unchanged output, got:\n%s", buf.String())
+		}
+	})
+
+	t.Run("Minimal Diff", func(t *testing.T) {
+		props, _ := ParseProperties(strings.NewReader(input))
+		props.Set("server.port", 8080)
+		props.Set("app.list", "c")
+		props.Delete("server.host")
+		props.Set("new key", "a=b")
+
+		var buf bytes.Buffer
+		if err := props.WriteProperties(&buf); err != nil {
+			t.Fatal(err)
+		}
+		expected := "# App\n" +
+			"app.name = Demo\n" +
+			"app.list = c\n" +
+			"\n" +
+			"! server\n" +
+			"server.port: 8080\n" +
+			"new\\ key=a\\=b\n" +
+			"# end\n"
+		if buf.String() != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+		}
+	})
+
+	t.Run("Escaping Round Trip", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set(" lead:key#", "  two spaces\tand\\ \n")
+		om.Set("ctrl", "\x01")
+
+		var buf bytes.Buffer
+		if err := om.WriteProperties(&buf); err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := ParseProperties(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !om.Equal(parsed) {
+			t.Errorf("Expected %s, got %s", om.String(), parsed.String())
+		}
+	})
+
+	t.Run("Env Layout Ignored", func(t *testing.T) {
+		env, _ := ParseEnv(strings.NewReader("# comment\nA=1\n"))
+		var buf bytes.Buffer
+		if err := env.WriteProperties(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != "A=1\n" {
+			t.Errorf("Expected A=1, got %q", buf.String())
+		}
+	})
+}
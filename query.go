@@ -0,0 +1,194 @@
+package orderedmap
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// maxFormSize bounds the request body read by ParseForm, as net/http does.
+const maxFormSize = 10 << 20
+
+// ParseQuery parses a URL-encoded query string, such as "b=2&a=1&a=3", into
+// a new OrderedMap in the order the keys first appear. A key that appears
+// once has a string value; a repeated key has a []string value holding all
+// of its values in order. A key without "=" has an empty value. The query
+// must not start with "?". Like url.ParseQuery, ParseQuery rejects
+// semicolons as separators.
+//
+// The returned map remembers the original text, so EncodeQuery returns it
+// unchanged unless the map is modified.
+//
+// Example:
+//
+//	params, err := ParseQuery("oauth_nonce=abc&oauth_timestamp=1700000000&tag=a&tag=b")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	tags, _ := params.Get("tag") // []string{"a", "b"}
+func ParseQuery(s string) (*OrderedMap, error) {
+	om := NewOrderedMap()
+	layout := newTextLayout("query")
+	layout.text = s
+
+	for _, segment := range strings.Split(s, "&") {
+		if segment == "" {
+			continue
+		}
+		if strings.Contains(segment, ";") {
+			return nil, fmt.Errorf("invalid semicolon separator in query")
+		}
+		rawKey, rawValue, _ := strings.Cut(segment, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query key %q: %w", rawKey, err)
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query value for %s: %w", key, err)
+		}
+
+		e, exists := layout.entries[key]
+		if !exists {
+			e = &layoutEntry{value: value, index: len(layout.entries)}
+			layout.entries[key] = e
+		} else if list, ok := e.value.([]string); ok {
+			e.value = append(list, value)
+		} else {
+			e.value = []string{e.value.(string), value}
+		}
+		e.raw = append(e.raw, segment)
+		_ = om.set(key, e.value)
+	}
+	for key, e := range layout.entries {
+		if list, ok := e.value.([]string); ok {
+			// Changing the slice in the map must not change the layout.
+			om.nodeMap[key].Value = slices.Clone(list)
+		}
+	}
+	om.layout = layout
+	return om, nil
+}
+
+// EncodeQuery encodes om as a URL query string in map order. Values are
+// formatted with fmt.Sprint; []string and []any values are written as one
+// key=value pair per element. Keys and values are escaped with
+// url.QueryEscape.
+//
+// If om was returned by ParseQuery and has not been changed, the original
+// text is returned. Otherwise unchanged entries keep their original encoding
+// and the pairs of a repeated key are written together. Unlike
+// url.Values.Encode, the keys are not sorted. This function is thread-safe.
+//
+// Example:
+//
+//	params := NewOrderedMap()
+//	params.Set("q", "ordered map")
+//	params.Set("page", 2)
+//	query := EncodeQuery(params) // "q=ordered+map&page=2"
+func EncodeQuery(om *OrderedMap) string {
+	om.rlock()
+	defer om.runlock()
+
+	layout := om.layoutFor("query")
+	if layout != nil && om.unchangedSince(layout) {
+		return layout.text
+	}
+
+	var sb strings.Builder
+	write := func(segment string) {
+		if sb.Len() > 0 {
+			sb.WriteByte('&')
+		}
+		sb.WriteString(segment)
+	}
+	for current := om.head; current != nil; current = current.next {
+		if layout != nil {
+			if e := layout.entries[current.Key]; e != nil && valuesEqual(current.Value, e.value) {
+				for _, segment := range e.raw {
+					write(segment)
+				}
+				continue
+			}
+		}
+		key := url.QueryEscape(textValue(current.Key))
+		switch v := current.Value.(type) {
+		case []string:
+			for _, elem := range v {
+				write(key + "=" + url.QueryEscape(elem))
+			}
+		case []any:
+			for _, elem := range v {
+				write(key + "=" + url.QueryEscape(textValue(elem)))
+			}
+		default:
+			write(key + "=" + url.QueryEscape(textValue(v)))
+		}
+	}
+	return sb.String()
+}
+
+// unchangedSince reports whether the map holds exactly the keys of layout,
+// in the same order and with the same values. The caller must hold om.mu.
+func (om *OrderedMap) unchangedSince(layout *textLayout) bool {
+	if om.length != len(layout.entries) {
+		return false
+	}
+	i := 0
+	for current := om.head; current != nil; current = current.next {
+		e := layout.entries[current.Key]
+		if e == nil || e.index != i || !valuesEqual(current.Value, e.value) {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// ParseForm parses the form values of r into a new OrderedMap, in order,
+// using the same rules as ParseQuery. For POST, PUT and PATCH requests with
+// an application/x-www-form-urlencoded body, the body is read (up to 10 MB)
+// and its values come first, followed by the URL query values, as in
+// r.Form. For other requests only the URL query is parsed.
+//
+// Like r.ParseForm, ParseForm consumes the request body.
+//
+// Example:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//	    form, err := ParseForm(r)
+//	    if err != nil {
+//	        http.Error(w, err.Error(), http.StatusBadRequest)
+//	        return
+//	    }
+//	    // form keeps the order the fields were submitted in
+//	}
+func ParseForm(r *http.Request) (*OrderedMap, error) {
+	var query string
+	if r.URL != nil {
+		query = r.URL.RawQuery
+	}
+	if r.Body == nil || (r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodPatch) {
+		return ParseQuery(query)
+	}
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || contentType != "application/x-www-form-urlencoded" {
+		return ParseQuery(query)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxFormSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxFormSize {
+		return nil, fmt.Errorf("form body too large")
+	}
+	if len(body) > 0 && query != "" {
+		return ParseQuery(string(body) + "&" + query)
+	}
+	return ParseQuery(string(body) + query)
+}
@@ -0,0 +1,135 @@
+package orderedmap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	t.Run("Order And Repeats", func(t *testing.T) {
+		params, err := ParseQuery("z=1&a=x+y&tag=a&flag&tag=b%26c&empty=")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []Pair{
+			{"z", "1"},
+			{"a", "x y"},
+			{"tag", []string{"a", "b&c"}},
+			{"flag", ""},
+			{"empty", ""},
+		}
+		if params.Len() != len(expected) {
+			t.Fatalf("Expected %d keys, got %d", len(expected), params.Len())
+		}
+		for i, key := range params.Keys() {
+			value, _ := params.Get(key)
+			if key != expected[i].Key || !valuesEqual(value, expected[i].Value) {
+				t.Errorf("Expected %v=%v, got %v=%v", expected[i].Key, expected[i].Value, key, value)
+			}
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, input := range []string{"a=1;b=2", "a=%zz", "%=1"} {
+			if _, err := ParseQuery(input); err == nil {
+				t.Errorf("Expected error for %q", input)
+			}
+		}
+	})
+}
+
+func TestEncodeQuery(t *testing.T) {
+	t.Run("Round Trip", func(t *testing.T) {
+		for _, input := range []string{
+			"oauth_nonce=abc&oauth_timestamp=1&tag=a&other=1&tag=b",
+			"q=a%20b&r=a+b&&flag",
+			"",
+		} {
+			params, err := ParseQuery(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if encoded := EncodeQuery(params); encoded != input {
+				t.Errorf("Expected %q, got %q", input, encoded)
+			}
+		}
+	})
+
+	t.Run("Modified", func(t *testing.T) {
+		params, _ := ParseQuery("q=a%20b&tag=1&page=1&tag=2")
+		params.Set("page", 2)
+		params.Set("new key", "x&y")
+		if encoded := EncodeQuery(params); encoded != "q=a%20b&tag=1&tag=2&page=2&new+key=x%26y" {
+			t.Errorf("Unexpected encoding %q", encoded)
+		}
+
+		tags, _ := params.Get("tag")
+		tags.([]string)[0] = "changed"
+		if encoded := EncodeQuery(params); !strings.Contains(encoded, "tag=changed&tag=2") {
+			t.Errorf("Expected changed tag, got %q", encoded)
+		}
+
+		params, _ = ParseQuery("a=1&b=2")
+		params.MoveToFront("b")
+		if encoded := EncodeQuery(params); encoded != "b=2&a=1" {
+			t.Errorf("Expected b=2&a=1, got %q", encoded)
+		}
+	})
+
+	t.Run("New Map", func(t *testing.T) {
+		params := NewOrderedMap()
+		params.Set("q", "ordered map")
+		params.Set("ids", []any{1, 2})
+		params.Set("none", nil)
+		if encoded := EncodeQuery(params); encoded != "q=ordered+map&ids=1&ids=2&none=" {
+			t.Errorf("Unexpected encoding %q", encoded)
+		}
+	})
+}
+
+func TestParseForm(t *testing.T) {
+	t.Run("Body Then Query", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/submit?source=web&b=3", strings.NewReader("b=1&a=2&b=2"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+		form, err := ParseForm(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := form.Get("b")
+		if keys := form.Keys(); len(keys) != 3 || keys[0] != "b" || keys[1] != "a" || keys[2] != "source" {
+			t.Errorf("Unexpected keys %v", keys)
+		}
+		if !valuesEqual(b, []string{"1", "2", "3"}) {
+			t.Errorf("Expected [1 2 3], got %v", b)
+		}
+	})
+
+	t.Run("Query Only", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/search?q=go&page=2", nil)
+		form, err := ParseForm(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if EncodeQuery(form) != "q=go&page=2" {
+			t.Errorf("Unexpected form %s", form.String())
+		}
+
+		r = httptest.NewRequest(http.MethodPost, "/upload?x=1", strings.NewReader(`{"a":1}`))
+		r.Header.Set("Content-Type", "application/json")
+		form, err = ParseForm(r)
+		if err != nil || form.String() != "{x: 1}" {
+			t.Errorf("Expected only query values, got %v, %v", form, err)
+		}
+	})
+
+	t.Run("Too Large", func(t *testing.T) {
+		body := strings.NewReader("a=" + strings.Repeat("x", maxFormSize))
+		r := httptest.NewRequest(http.MethodPost, "/", body)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if _, err := ParseForm(r); err == nil {
+			t.Error("Expected error for a large body")
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package orderedmap
+
+import "unsafe"
+
+// Union returns a new map with the keys of both a and b. Keys appear in a's
+// order, followed by the keys only in b, in b's order. For keys present in
+// both maps, the value is resolve(key, aValue, bValue); if resolve is nil,
+// a's value is kept.
+//
+// Both maps are read-locked for the whole operation, so each is seen in a
+// consistent state. a and b may be the same map. resolve must not modify
+// either map. This function is thread-safe.
+//
+// Example:
+//
+//	// Flags from overrides win
+//	flags := Union(defaults, overrides, func(key, a, b any) any { return b })
+func Union(a, b *OrderedMap, resolve func(key, aValue, bValue any) any) *OrderedMap {
+	defer rlockPair(a, b)()
+
+	result := a.derive(a.length + b.length)
+	for current := a.head; current != nil; current = current.next {
+		value := current.Value
+		if other, exists := b.lookup(current.Key); exists && resolve != nil {
+			value = resolve(current.Key, current.Value, other.Value)
+		}
+		_ = result.setNormalized(current.Key, value)
+	}
+	for current := b.head; current != nil; current = current.next {
+		if _, exists := a.lookup(current.Key); !exists {
+			_ = result.set(current.Key, current.Value)
+		}
+	}
+	return result
+}
+
+// Intersect returns a new map with the keys present in both a and b, in a's
+// order. The value is resolve(key, aValue, bValue); if resolve is nil, a's
+// value is kept. a and b may be the same map. resolve must not modify
+// either map. This function is thread-safe.
+//
+// Example:
+//
+//	// Permissions granted by both roles, with the stricter limit
+//	common := Intersect(roleA, roleB, func(key, a, b any) any {
+//	    return min(a.(int), b.(int))
+//	})
+func Intersect(a, b *OrderedMap, resolve func(key, aValue, bValue any) any) *OrderedMap {
+	defer rlockPair(a, b)()
+
+	result := a.derive(0)
+	for current := a.head; current != nil; current = current.next {
+		other, exists := b.lookup(current.Key)
+		if !exists {
+			continue
+		}
+		value := current.Value
+		if resolve != nil {
+			value = resolve(current.Key, current.Value, other.Value)
+		}
+		_ = result.setNormalized(current.Key, value)
+	}
+	return result
+}
+
+// Difference returns a new map with the entries of a whose keys are not in
+// b, in a's order. a and b may be the same map. This function is
+// thread-safe.
+//
+// Example:
+//
+//	removed := Difference(before, after)
+func Difference(a, b *OrderedMap) *OrderedMap {
+	defer rlockPair(a, b)()
+
+	result := a.derive(0)
+	appendMissing(a, b, result.setNormalized)
+	return result
+}
+
+// SymmetricDifference returns a new map with the entries whose keys are in
+// exactly one of a and b: first those of a, in a's order, then those of b,
+// in b's order. a and b may be the same map. This function is thread-safe.
+//
+// Example:
+//
+//	changedFlags := SymmetricDifference(oldFlags, newFlags)
+func SymmetricDifference(a, b *OrderedMap) *OrderedMap {
+	defer rlockPair(a, b)()
+
+	result := a.derive(0)
+	appendMissing(a, b, result.setNormalized)
+	appendMissing(b, a, result.set)
+	return result
+}
+
+// appendMissing adds the entries of from whose keys are not in other to a
+// result map with set, which is the result's setNormalized for keys that
+// were normalized by the result's normalizer already.
+func appendMissing(from, other *OrderedMap, set func(key, value any) error) {
+	for current := from.head; current != nil; current = current.next {
+		if _, exists := other.lookup(current.Key); !exists {
+			_ = set(current.Key, current.Value)
+		}
+	}
+}
+
+// rlockPair read-locks a and b and returns a function that unlocks them.
+// The maps are always locked in address order, so two calls with the same
+// maps in opposite order cannot deadlock behind waiting writers, and a map
+// passed twice is locked only once.
+func rlockPair(a, b *OrderedMap) (unlock func()) {
+	if a == b {
+		a.rlock()
+		return a.runlock
+	}
+	first, second := a, b
+	if uintptr(unsafe.Pointer(second)) < uintptr(unsafe.Pointer(first)) {
+		first, second = second, first
+	}
+	first.rlock()
+	second.rlock()
+	return func() {
+		second.runlock()
+		first.runlock()
+	}
+}
@@ -0,0 +1,127 @@
+package orderedmap
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestUnion(t *testing.T) {
+	a := NewOrderedMap()
+	a.Set("read", 1)
+	a.Set("write", 2)
+	a.Set("admin", 3)
+
+	b := NewOrderedMap()
+	b.Set("audit", 10)
+	b.Set("admin", 30)
+	b.Set("read", 10)
+
+	u := Union(a, b, nil)
+	if u.String() != "{read: 1, write: 2, admin: 3, audit: 10}" {
+		t.Errorf("Unexpected union %s", u.String())
+	}
+
+	sum := Union(a, b, func(key, av, bv any) any { return av.(int) + bv.(int) })
+	if sum.String() != "{read: 11, write: 2, admin: 33, audit: 10}" {
+		t.Errorf("Unexpected resolved union %s", sum.String())
+	}
+
+	if a.Len() != 3 || b.Len() != 3 {
+		t.Error("Expected operands to be unchanged")
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	a := NewOrderedMap()
+	a.Set("read", 1)
+	a.Set("write", 2)
+	a.Set("admin", 3)
+
+	b := NewOrderedMap()
+	b.Set("audit", 10)
+	b.Set("admin", 30)
+	b.Set("read", 10)
+
+	i := Intersect(a, b, nil)
+	if i.String() != "{read: 1, admin: 3}" {
+		t.Errorf("Unexpected intersection %s", i.String())
+	}
+	right := Intersect(a, b, func(key, av, bv any) any { return bv })
+	if right.String() != "{read: 10, admin: 30}" {
+		t.Errorf("Unexpected resolved intersection %s", right.String())
+	}
+	if Intersect(a, NewOrderedMap(), nil).Len() != 0 {
+		t.Error("Expected empty intersection with an empty map")
+	}
+}
+
+func TestDifference(t *testing.T) {
+	a := NewOrderedMap()
+	a.Set("read", 1)
+	a.Set("write", 2)
+	a.Set("admin", 3)
+
+	b := NewOrderedMap()
+	b.Set("audit", 10)
+	b.Set("admin", 30)
+	b.Set("read", 10)
+
+	if d := Difference(a, b); d.String() != "{write: 2}" {
+		t.Errorf("Unexpected difference %s", d.String())
+	}
+	if d := Difference(b, a); d.String() != "{audit: 10}" {
+		t.Errorf("Unexpected difference %s", d.String())
+	}
+	if s := SymmetricDifference(a, b); !reflect.DeepEqual(s.Keys(), []any{"write", "audit"}) {
+		t.Errorf("Unexpected symmetric difference %s", s.String())
+	}
+}
+
+func TestSetOps_SameMap(t *testing.T) {
+	a := NewOrderedMap()
+	a.Set("read", 1)
+	a.Set("write", 2)
+	a.Set("admin", 3)
+
+	if u := Union(a, a, func(key, av, bv any) any { return av.(int) * 2 }); u.String() != "{read: 2, write: 4, admin: 6}" {
+		t.Errorf("Unexpected union %s", u.String())
+	}
+	if i := Intersect(a, a, nil); i.Len() != 3 {
+		t.Errorf("Expected 3 elements, got %d", i.Len())
+	}
+	if Difference(a, a).Len() != 0 || SymmetricDifference(a, a).Len() != 0 {
+		t.Error("Expected empty differences of a map with itself")
+	}
+}
+
+func TestSetOps_Concurrent(t *testing.T) {
+	a := NewOrderedMap()
+	a.Set("read", 1)
+	a.Set("write", 2)
+	a.Set("admin", 3)
+
+	b := NewOrderedMap()
+	b.Set("audit", 10)
+	b.Set("admin", 30)
+	b.Set("read", 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			Union(a, b, nil)
+		}()
+		go func() {
+			defer wg.Done()
+			SymmetricDifference(b, a)
+		}()
+		go func(i int) {
+			defer wg.Done()
+			a.Set(i, i)
+			b.Set(i, i)
+		}(i)
+	}
+	wg.Wait()
+}
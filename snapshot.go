@@ -0,0 +1,266 @@
+package orderedmap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// Binary snapshot format
+//
+// A snapshot is a self-describing byte stream. All fixed-size integers are
+// big endian.
+//
+//	offset  size  field
+//	0       4     magic "OMAP"
+//	4       2     format version (currently 1)
+//	6       2     flags (reserved, must be zero)
+//	8       8     entry count
+//	16      ...   entries, in map order
+//	...     4     CRC-32 (IEEE) of every preceding byte
+//
+// Each entry is a uvarint key length, the encoded key, a uvarint value length
+// and the encoded value. Keys and values are encoded by a Codec, BinaryCodec
+// unless another one is supplied.
+
+const (
+	snapshotMagic   = "OMAP"
+	snapshotVersion = 1
+
+	// snapshotHeaderSize is the size of the fixed header in bytes.
+	snapshotHeaderSize = 16
+
+	// maxSnapshotField bounds the size of a single encoded key or value so a
+	// corrupt length prefix cannot trigger a huge allocation.
+	maxSnapshotField = 1 << 30
+
+	// maxSnapshotPresize bounds the capacity hint taken from the header.
+	maxSnapshotPresize = 1 << 24
+)
+
+var (
+	// ErrInvalidSnapshot is returned when the input is not a snapshot or is malformed.
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
+	// ErrSnapshotChecksum is returned when the trailing checksum does not match the content.
+	ErrSnapshotChecksum = errors.New("snapshot checksum mismatch")
+)
+
+// WriteSnapshot writes the map to w in the binary snapshot format using
+// BinaryCodec. The output preserves the order of the entries and the exact
+// types of supported keys and values.
+// This method is thread-safe and holds a read lock while writing.
+//
+// Example:
+//
+//	f, err := os.Create("state.omap")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer f.Close()
+//	if err := om.WriteSnapshot(f); err != nil {
+//	    log.Fatal(err)
+//	}
+func (om *OrderedMap) WriteSnapshot(w io.Writer) error {
+	return om.WriteSnapshotWithCodec(w, BinaryCodec{})
+}
+
+// WriteSnapshotWithCodec is like WriteSnapshot but encodes keys and values
+// with the given codec. The same codec must be used to read the snapshot back.
+func (om *OrderedMap) WriteSnapshotWithCodec(w io.Writer, codec Codec) error {
+	om.rlock()
+	defer om.runlock()
+	return writeSnapshot(w, codec, om.head, om.length)
+}
+
+// ReadSnapshot replaces the contents of the map with the entries stored in a
+// snapshot produced by WriteSnapshot. The node index and the linked list are
+// rebuilt in a single pass, pre-sized to the stored entry count. If the
+// snapshot is malformed or its checksum does not match, an error is returned
+// and the map is left unchanged.
+// This method is thread-safe.
+//
+// Example:
+//
+//	f, err := os.Open("state.omap")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer f.Close()
+//	om := NewOrderedMap()
+//	if err := om.ReadSnapshot(f); err != nil {
+//	    log.Fatal(err)
+//	}
+func (om *OrderedMap) ReadSnapshot(r io.Reader) error {
+	return om.ReadSnapshotWithCodec(r, BinaryCodec{})
+}
+
+// ReadSnapshotWithCodec is like ReadSnapshot but decodes keys and values with
+// the given codec.
+func (om *OrderedMap) ReadSnapshotWithCodec(r io.Reader, codec Codec) error {
+	restored, err := readSnapshot(r, codec)
+	if err != nil {
+		return err
+	}
+
+	om.lock()
+	defer om.unlock()
+	om.reset()
+	if om.normalize != nil || om.maxLen > 0 {
+		// Keys must go through the map's normalizer and length limit.
+		for current := restored.head; current != nil; current = current.next {
+			_ = om.setNormalized(om.normalizeKey(current.Key), current.Value)
+		}
+		return nil
+	}
+	om.head = restored.head
+	om.tail = restored.tail
+	om.nodeMap = restored.nodeMap
+	om.length = restored.length
+	for current := om.head; current != nil; current = current.next {
+		// The stamps come from restored; cursors compare them with om.seq.
+		om.touch(current)
+		if om.observers != nil {
+			om.emit(Event{Type: EventInserted, Key: current.Key, Value: current.Value, After: prevKey(current)})
+		}
+	}
+	return nil
+}
+
+func writeSnapshot(w io.Writer, codec Codec, head *Node, length int) error {
+	crc := crc32.NewIEEE()
+	bw := bufio.NewWriter(w)
+	out := io.MultiWriter(bw, crc)
+
+	var header [snapshotHeaderSize]byte
+	copy(header[:4], snapshotMagic)
+	binary.BigEndian.PutUint16(header[4:6], snapshotVersion)
+	binary.BigEndian.PutUint64(header[8:16], uint64(length))
+	if _, err := out.Write(header[:]); err != nil {
+		return err
+	}
+
+	var lenBuf [binary.MaxVarintLen64]byte
+	writeField := func(v any) error {
+		data, err := codec.Encode(v)
+		if err != nil {
+			return err
+		}
+		n := binary.PutUvarint(lenBuf[:], uint64(len(data)))
+		if _, err := out.Write(lenBuf[:n]); err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	for current := head; current != nil; current = current.next {
+		if err := writeField(current.Key); err != nil {
+			return fmt.Errorf("encoding key %v: %w", current.Key, err)
+		}
+		if err := writeField(current.Value); err != nil {
+			return fmt.Errorf("encoding value for key %v: %w", current.Key, err)
+		}
+	}
+
+	var trailer [4]byte
+	binary.BigEndian.PutUint32(trailer[:], crc.Sum32())
+	if _, err := bw.Write(trailer[:]); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// checksumReader feeds every byte it returns into a running checksum.
+type checksumReader struct {
+	r *bufio.Reader
+	h hash.Hash32
+}
+
+func (cr *checksumReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.h.Write(p[:n])
+	return n, err
+}
+
+func (cr *checksumReader) ReadByte() (byte, error) {
+	b, err := cr.r.ReadByte()
+	if err == nil {
+		cr.h.Write([]byte{b})
+	}
+	return b, err
+}
+
+func readSnapshot(r io.Reader, codec Codec) (*OrderedMap, error) {
+	cr := &checksumReader{r: bufio.NewReader(r), h: crc32.NewIEEE()}
+
+	var header [snapshotHeaderSize]byte
+	if _, err := io.ReadFull(cr, header[:]); err != nil {
+		return nil, fmt.Errorf("%w: reading header: %v", ErrInvalidSnapshot, err)
+	}
+	if string(header[:4]) != snapshotMagic {
+		return nil, fmt.Errorf("%w: bad magic %q", ErrInvalidSnapshot, header[:4])
+	}
+	if version := binary.BigEndian.Uint16(header[4:6]); version != snapshotVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidSnapshot, version)
+	}
+	if flags := binary.BigEndian.Uint16(header[6:8]); flags != 0 {
+		return nil, fmt.Errorf("%w: unknown flags %#x", ErrInvalidSnapshot, flags)
+	}
+	count := binary.BigEndian.Uint64(header[8:16])
+
+	presize := count
+	if presize > maxSnapshotPresize {
+		presize = maxSnapshotPresize
+	}
+	restored := &OrderedMap{nodeMap: make(map[any]*Node, presize)}
+
+	readField := func() (any, error) {
+		n, err := binary.ReadUvarint(cr)
+		if err != nil {
+			return nil, err
+		}
+		if n > maxSnapshotField {
+			return nil, fmt.Errorf("field length %d exceeds limit", n)
+		}
+		data := make([]byte, n)
+		if _, err := io.ReadFull(cr, data); err != nil {
+			return nil, err
+		}
+		return codec.Decode(data)
+	}
+
+	for i := uint64(0); i < count; i++ {
+		key, err := readField()
+		if err != nil {
+			return nil, fmt.Errorf("%w: entry %d key: %v", ErrInvalidSnapshot, i, err)
+		}
+		value, err := readField()
+		if err != nil {
+			return nil, fmt.Errorf("%w: entry %d value: %v", ErrInvalidSnapshot, i, err)
+		}
+		if key == nil {
+			return nil, fmt.Errorf("%w: entry %d has a nil key", ErrInvalidSnapshot, i)
+		}
+		if !isHashable(key) {
+			return nil, fmt.Errorf("%w: entry %d has unhashable key type %T", ErrInvalidSnapshot, i, key)
+		}
+		if _, exists := restored.nodeMap[key]; exists {
+			return nil, fmt.Errorf("%w: duplicate key %v", ErrInvalidSnapshot, key)
+		}
+		_ = restored.set(key, value)
+	}
+
+	sum := cr.h.Sum32()
+	var trailer [4]byte
+	if _, err := io.ReadFull(cr.r, trailer[:]); err != nil {
+		return nil, fmt.Errorf("%w: reading checksum: %v", ErrInvalidSnapshot, err)
+	}
+	if binary.BigEndian.Uint32(trailer[:]) != sum {
+		return nil, ErrSnapshotChecksum
+	}
+	return restored, nil
+}
@@ -0,0 +1,251 @@
+package orderedmap
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOrderedMap_SnapshotRoundTrip(t *testing.T) {
+	nested := NewOrderedMap()
+	nested.Set("z", 1)
+	nested.Set("a", 2)
+
+	om := NewOrderedMap()
+	entries := []struct {
+		key   any
+		value any
+	}{
+		{"string", "value"},
+		{42, int64(7)},
+		{int8(-3), uint16(9)},
+		{uint(5), float32(1.5)},
+		{3.25, true},
+		{"nil", nil},
+		{"bytes", []byte{1, 2, 3}},
+		{"slice", []any{"a", 1, false}},
+		{"map", map[string]any{"b": 2, "a": 1}},
+		{"nested", nested},
+	}
+	for _, e := range entries {
+		om.Set(e.key, e.value)
+	}
+
+	var buf bytes.Buffer
+	if err := om.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("WriteSnapshot failed: %v", err)
+	}
+
+	restored := NewOrderedMap()
+	restored.Set("stale", "entry")
+	if err := restored.ReadSnapshot(&buf); err != nil {
+		t.Fatalf("ReadSnapshot failed: %v", err)
+	}
+
+	if restored.Len() != len(entries) {
+		t.Fatalf("Expected %d entries, got %d", len(entries), restored.Len())
+	}
+	if restored.Has("stale") {
+		t.Error("Expected previous contents to be replaced")
+	}
+
+	keys := restored.Keys()
+	for i, e := range entries {
+		if keys[i] != e.key {
+			t.Errorf("Expected key %v at position %d, got %v", e.key, i, keys[i])
+		}
+		got, _ := restored.Get(e.key)
+		if e.key == "nested" {
+			gotMap, ok := got.(*OrderedMap)
+			if !ok || gotMap.String() != nested.String() {
+				t.Errorf("Expected nested map %v, got %v", nested, got)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, e.value) {
+			t.Errorf("Expected %#v for key %v, got %#v", e.value, e.key, got)
+		}
+	}
+}
+
+func TestOrderedMap_SnapshotEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewOrderedMap().WriteSnapshot(&buf); err != nil {
+		t.Fatalf("WriteSnapshot failed: %v", err)
+	}
+	if buf.Len() != snapshotHeaderSize+4 {
+		t.Errorf("Expected %d bytes for empty snapshot, got %d", snapshotHeaderSize+4, buf.Len())
+	}
+
+	var om OrderedMap
+	if err := om.ReadSnapshot(&buf); err != nil {
+		t.Fatalf("ReadSnapshot failed: %v", err)
+	}
+	if om.Len() != 0 {
+		t.Errorf("Expected empty map, got %d entries", om.Len())
+	}
+	om.Set("after", 1)
+	if om.Len() != 1 {
+		t.Error("Expected restored map to be usable")
+	}
+}
+
+func TestOrderedMap_SnapshotCorruption(t *testing.T) {
+	om := NewOrderedMap()
+	for i := 0; i < 10; i++ {
+		om.Set(i, i*i)
+	}
+	var buf bytes.Buffer
+	if err := om.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("WriteSnapshot failed: %v", err)
+	}
+	valid := buf.Bytes()
+
+	t.Run("Flipped Byte", func(t *testing.T) {
+		data := append([]byte(nil), valid...)
+		data[snapshotHeaderSize+3] ^= 0xff
+		target := NewOrderedMap()
+		target.Set("keep", true)
+		err := target.ReadSnapshot(bytes.NewReader(data))
+		if err == nil {
+			t.Fatal("Expected error for corrupted snapshot")
+		}
+		if !errors.Is(err, ErrSnapshotChecksum) && !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("Unexpected error type: %v", err)
+		}
+		if target.Len() != 1 || !target.Has("keep") {
+			t.Error("Expected map to be unchanged after failed restore")
+		}
+	})
+
+	t.Run("Checksum Mismatch", func(t *testing.T) {
+		data := append([]byte(nil), valid...)
+		data[len(data)-1] ^= 0x01
+		err := NewOrderedMap().ReadSnapshot(bytes.NewReader(data))
+		if !errors.Is(err, ErrSnapshotChecksum) {
+			t.Errorf("Expected ErrSnapshotChecksum, got %v", err)
+		}
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		for _, n := range []int{0, 3, snapshotHeaderSize, len(valid) - 5, len(valid) - 1} {
+			err := NewOrderedMap().ReadSnapshot(bytes.NewReader(valid[:n]))
+			if !errors.Is(err, ErrInvalidSnapshot) {
+				t.Errorf("Expected ErrInvalidSnapshot for %d bytes, got %v", n, err)
+			}
+		}
+	})
+
+	t.Run("Bad Magic", func(t *testing.T) {
+		data := append([]byte(nil), valid...)
+		copy(data, "JSON")
+		err := NewOrderedMap().ReadSnapshot(bytes.NewReader(data))
+		if !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("Expected ErrInvalidSnapshot, got %v", err)
+		}
+	})
+
+	t.Run("Future Version", func(t *testing.T) {
+		data := append([]byte(nil), valid...)
+		data[5] = snapshotVersion + 1
+		err := NewOrderedMap().ReadSnapshot(bytes.NewReader(data))
+		if !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("Expected ErrInvalidSnapshot, got %v", err)
+		}
+	})
+}
+
+func TestOrderedMap_SnapshotUnsupportedType(t *testing.T) {
+	om := NewOrderedMap()
+	om.Set("fn", func() {})
+	if err := om.WriteSnapshot(&bytes.Buffer{}); err == nil {
+		t.Error("Expected error for unsupported value type")
+	}
+}
+
+type stringCodec struct{}
+
+func (stringCodec) Encode(v any) ([]byte, error)    { return []byte(v.(string)), nil }
+func (stringCodec) Decode(data []byte) (any, error) { return string(data), nil }
+
+func TestOrderedMap_SnapshotCustomCodec(t *testing.T) {
+	om := NewOrderedMap()
+	om.Set("b", "2")
+	om.Set("a", "1")
+
+	var buf bytes.Buffer
+	if err := om.WriteSnapshotWithCodec(&buf, stringCodec{}); err != nil {
+		t.Fatalf("WriteSnapshotWithCodec failed: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte{1, 'b', 1, '2'}) {
+		t.Error("Expected custom codec output in snapshot")
+	}
+
+	restored := NewOrderedMap()
+	if err := restored.ReadSnapshotWithCodec(&buf, stringCodec{}); err != nil {
+		t.Fatalf("ReadSnapshotWithCodec failed: %v", err)
+	}
+	if restored.String() != "{b: 2, a: 1}" {
+		t.Errorf("Expected {b: 2, a: 1}, got %s", restored.String())
+	}
+}
+
+func TestBinaryCodec_Errors(t *testing.T) {
+	codec := BinaryCodec{}
+	inputs := [][]byte{
+		{},
+		{tagInt64, 1, 2},
+		{tagString, 10, 'a'},
+		{tagSlice, 200},
+		{255},
+		{tagTrue, 0},
+		{tagOrderedMap, 1, tagSlice, 0, tagNil},
+	}
+	for _, in := range inputs {
+		if _, err := codec.Decode(in); err == nil {
+			t.Errorf("Expected error decoding %v", in)
+		}
+	}
+}
+
+func TestBinaryCodec_Cycle(t *testing.T) {
+	codec := BinaryCodec{}
+
+	t.Run("Nested Map", func(t *testing.T) {
+		om := NewOrderedMap()
+		inner := NewOrderedMap()
+		om.Set("inner", inner)
+		inner.Set("outer", om)
+		if _, err := codec.Encode(om); err == nil {
+			t.Error("Expected error for a cyclic map")
+		}
+	})
+
+	t.Run("Slice", func(t *testing.T) {
+		s := make([]any, 1)
+		s[0] = s
+		if _, err := codec.Encode(s); err == nil {
+			t.Error("Expected error for a cyclic slice")
+		}
+	})
+
+	t.Run("Shared Map", func(t *testing.T) {
+		shared := NewOrderedMap()
+		shared.Set("x", 1)
+		om := NewOrderedMap()
+		om.Set("a", shared)
+		om.Set("b", shared)
+		data, err := codec.Encode(om)
+		if err != nil {
+			t.Fatalf("Expected a shared map to encode, got %v", err)
+		}
+		v, err := codec.Decode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := v.(*OrderedMap).String(); s != "{a: {x: 1}, b: {x: 1}}" {
+			t.Errorf("Expected {a: {x: 1}, b: {x: 1}}, got %s", s)
+		}
+	})
+}
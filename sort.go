@@ -0,0 +1,187 @@
+package orderedmap
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Entry is a key-value pair as seen by the sort comparators. It is the same
+// type as Pair.
+type Entry = Pair
+
+// SortFunc reorders the map in place so that entries are in ascending order
+// as defined by cmp, which must return a negative number when a < b, a
+// positive number when a > b and zero otherwise. The sort is not stable.
+// Existing nodes are relinked under a single write lock, so element handles
+// and cursors stay valid, and every entry that changes position is reported
+// as an EventMoved. cmp must not access the map.
+// This method is thread-safe.
+//
+// Example:
+//
+//	// Highest score first
+//	om.SortFunc(func(a, b Entry) int {
+//	    return cmp.Compare(b.Value.(int), a.Value.(int))
+//	})
+func (om *OrderedMap) SortFunc(cmp func(a, b Entry) int) {
+	om.lock()
+	defer om.unlock()
+	om.sortNodes(func(nodes []*Node) {
+		slices.SortFunc(nodes, func(a, b *Node) int {
+			return cmp(Entry{a.Key, a.Value}, Entry{b.Key, b.Value})
+		})
+	})
+}
+
+// SortStableFunc is like SortFunc but keeps entries that compare equal in
+// their current order, so multi-criteria sorts can be built from several
+// passes. This method is thread-safe.
+//
+// Example:
+//
+//	// By department, and by name within each department
+//	om.SortStableFunc(byName)
+//	om.SortStableFunc(byDepartment)
+func (om *OrderedMap) SortStableFunc(cmp func(a, b Entry) int) {
+	om.lock()
+	defer om.unlock()
+	om.sortNodes(func(nodes []*Node) {
+		slices.SortStableFunc(nodes, func(a, b *Node) int {
+			return cmp(Entry{a.Key, a.Value}, Entry{b.Key, b.Value})
+		})
+	})
+}
+
+// SortKeys reorders the map in place by ascending key. All keys must be
+// strings, all signed integers, all unsigned integers or all floating-point
+// numbers (types derived from them are fine); otherwise an error is returned
+// and the map is left unchanged. This method is thread-safe.
+//
+// Example:
+//
+//	om.Set("b", 2)
+//	om.Set("a", 1)
+//	om.SortKeys() // {a: 1, b: 2}
+func (om *OrderedMap) SortKeys() error {
+	om.lock()
+	defer om.unlock()
+	return om.sortOrdered(func(n *Node) any { return n.Key }, "key")
+}
+
+// SortValues reorders the map in place by ascending value. Entries with
+// equal values keep their current order. Values must satisfy the same rules
+// as keys for SortKeys. This method is thread-safe.
+//
+// Example:
+//
+//	om.Set("a", 3)
+//	om.Set("b", 1)
+//	om.SortValues() // {b: 1, a: 3}
+func (om *OrderedMap) SortValues() error {
+	om.lock()
+	defer om.unlock()
+	return om.sortOrdered(func(n *Node) any { return n.Value }, "value")
+}
+
+// sortOrdered sorts the nodes stably by the ordered value field returns.
+func (om *OrderedMap) sortOrdered(field func(*Node) any, what string) error {
+	var kind orderedKind
+	for current := om.head; current != nil; current = current.next {
+		k := kindOf(field(current))
+		if k == notOrdered {
+			return fmt.Errorf("cannot sort by %s: %v has unordered type %T", what, field(current), field(current))
+		}
+		if kind != notOrdered && k != kind {
+			return fmt.Errorf("cannot sort by %s: mixed types, found %T", what, field(current))
+		}
+		kind = k
+	}
+
+	om.sortNodes(func(nodes []*Node) {
+		slices.SortStableFunc(nodes, func(a, b *Node) int {
+			return compareOrdered(kind, field(a), field(b))
+		})
+	})
+	return nil
+}
+
+// sortNodes collects the nodes, lets sort reorder them and relinks the list
+// in the new order. The caller must hold om.mu for writing.
+func (om *OrderedMap) sortNodes(sort func(nodes []*Node)) {
+	if om.length < 2 {
+		return
+	}
+
+	before := make([]*Node, 0, om.length)
+	for current := om.head; current != nil; current = current.next {
+		before = append(before, current)
+	}
+	nodes := slices.Clone(before)
+	sort(nodes)
+
+	om.prepareWrite()
+	var prev *Node
+	for _, node := range nodes {
+		om.touch(node)
+		node.prev = prev
+		if prev == nil {
+			om.head = node
+		} else {
+			prev.next = node
+		}
+		prev = node
+	}
+	prev.next = nil
+	om.tail = prev
+
+	for i, node := range nodes {
+		if node != before[i] {
+			om.emit(Event{Type: EventMoved, Key: node.Key, Value: node.Value, After: prevKey(node)})
+		}
+	}
+}
+
+type orderedKind int
+
+const (
+	notOrdered orderedKind = iota
+	signedKind
+	unsignedKind
+	floatKind
+	stringKind
+)
+
+func kindOf(v any) orderedKind {
+	if v == nil {
+		return notOrdered
+	}
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return signedKind
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return unsignedKind
+	case reflect.Float32, reflect.Float64:
+		return floatKind
+	case reflect.String:
+		return stringKind
+	default:
+		return notOrdered
+	}
+}
+
+// compareOrdered compares two values of the given kind.
+func compareOrdered(kind orderedKind, a, b any) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch kind {
+	case signedKind:
+		return cmp.Compare(va.Int(), vb.Int())
+	case unsignedKind:
+		return cmp.Compare(va.Uint(), vb.Uint())
+	case floatKind:
+		return cmp.Compare(va.Float(), vb.Float())
+	default:
+		return cmp.Compare(va.String(), vb.String())
+	}
+}
@@ -0,0 +1,160 @@
+package orderedmap
+
+import (
+	"cmp"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOrderedMap_SortFunc(t *testing.T) {
+	om := NewOrderedMap()
+	om.Set("carol", 72)
+	om.Set("alice", 90)
+	om.Set("bob", 85)
+
+	e := om.GetElement("bob")
+	snap := om.Snapshot()
+
+	om.SortFunc(func(a, b Entry) int {
+		return cmp.Compare(b.Value.(int), a.Value.(int))
+	})
+	if !reflect.DeepEqual(om.Keys(), []any{"alice", "bob", "carol"}) {
+		t.Errorf("Unexpected keys %v", om.Keys())
+	}
+	if k, _, _ := om.First(); k != "alice" {
+		t.Errorf("Expected head alice, got %v", k)
+	}
+	if k, _, _ := om.Last(); k != "carol" {
+		t.Errorf("Expected tail carol, got %v", k)
+	}
+	if e.Prev().Key() != "alice" || e.Next().Key() != "carol" {
+		t.Error("Expected element handles to stay valid across a sort")
+	}
+	if snap.String() != "{carol: 72, alice: 90, bob: 85}" {
+		t.Errorf("Snapshot changed: %s", snap.String())
+	}
+	if v, _ := om.Get("bob"); v != 85 {
+		t.Errorf("Expected lookups to keep working, got %v", v)
+	}
+
+	var keys []any
+	for c := om.Back(); c != nil; c = c.Prev() {
+		keys = append(keys, c.Key())
+	}
+	if !reflect.DeepEqual(keys, []any{"carol", "bob", "alice"}) {
+		t.Errorf("Expected backward links to be rebuilt, got %v", keys)
+	}
+}
+
+func TestOrderedMap_SortStableFunc(t *testing.T) {
+	type employee struct {
+		dept string
+		name string
+	}
+	om := NewOrderedMap()
+	om.Set(1, employee{"eng", "zoe"})
+	om.Set(2, employee{"ops", "adam"})
+	om.Set(3, employee{"eng", "bea"})
+	om.Set(4, employee{"ops", "carl"})
+
+	om.SortStableFunc(func(a, b Entry) int {
+		return strings.Compare(a.Value.(employee).name, b.Value.(employee).name)
+	})
+	om.SortStableFunc(func(a, b Entry) int {
+		return strings.Compare(a.Value.(employee).dept, b.Value.(employee).dept)
+	})
+	if !reflect.DeepEqual(om.Keys(), []any{3, 1, 2, 4}) {
+		t.Errorf("Unexpected multi-criteria order %v", om.Keys())
+	}
+}
+
+func TestOrderedMap_SortKeysValues(t *testing.T) {
+	t.Run("Keys", func(t *testing.T) {
+		om := NewOrderedMap()
+		for _, k := range []int{3, -1, 2, 10} {
+			om.Set(k, k)
+		}
+		if err := om.SortKeys(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(om.Keys(), []any{-1, 2, 3, 10}) {
+			t.Errorf("Unexpected keys %v", om.Keys())
+		}
+
+		words := NewOrderedMap()
+		words.Set("pear", 1)
+		words.Set("apple", 2)
+		words.Set("fig", 3)
+		words.SortKeys()
+		if !reflect.DeepEqual(words.Keys(), []any{"apple", "fig", "pear"}) {
+			t.Errorf("Unexpected keys %v", words.Keys())
+		}
+	})
+
+	t.Run("Values Are Stable", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set("a", 2.5)
+		om.Set("b", 1.0)
+		om.Set("c", 2.5)
+		om.Set("d", -3.0)
+		if err := om.SortValues(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(om.Keys(), []any{"d", "b", "a", "c"}) {
+			t.Errorf("Unexpected keys %v", om.Keys())
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set("b", 1)
+		om.Set(1, 2)
+		if err := om.SortKeys(); err == nil {
+			t.Error("Expected error for mixed key types")
+		}
+		om.Set("c", []int{1})
+		if err := om.SortValues(); err == nil {
+			t.Error("Expected error for unordered value type")
+		}
+		if !reflect.DeepEqual(om.Keys(), []any{"b", 1, "c"}) {
+			t.Errorf("Expected map to be unchanged, got %v", om.Keys())
+		}
+	})
+
+	t.Run("Derived Types", func(t *testing.T) {
+		type id uint16
+		om := NewOrderedMap()
+		om.Set(id(7), nil)
+		om.Set(id(3), nil)
+		if err := om.SortKeys(); err != nil {
+			t.Fatal(err)
+		}
+		if k, _, _ := om.First(); k != id(3) {
+			t.Errorf("Expected first 3, got %v", k)
+		}
+	})
+}
+
+func TestOrderedMap_SortEvents(t *testing.T) {
+	om := NewOrderedMap()
+	om.Set("a", 1)
+	om.Set("c", 3)
+	om.Set("b", 2)
+	var moved []any
+	om.OnChange(func(ev Event) {
+		if ev.Type == EventMoved {
+			moved = append(moved, ev.Key)
+		}
+	})
+
+	om.SortKeys()
+	if !reflect.DeepEqual(moved, []any{"b", "c"}) {
+		t.Errorf("Expected moves for b and c, got %v", moved)
+	}
+	moved = nil
+	om.SortKeys()
+	if len(moved) != 0 {
+		t.Errorf("Expected no moves for an already sorted map, got %v", moved)
+	}
+}
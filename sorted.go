@@ -0,0 +1,550 @@
+package orderedmap
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+)
+
+const (
+	sortedMaxLevel = 32 // Enough for 4^32 entries with p = 1/4
+	sortedP        = 4  // One in sortedP nodes is promoted to the next level
+)
+
+// sortedNode is a node of the skip list behind SortedMap.
+type sortedNode struct {
+	key   any
+	value any
+	prev  *sortedNode   // Previous node on the bottom level, nil for the first node
+	next  []*sortedNode // Next node on each level the node takes part in
+}
+
+// SortedMap is a thread-safe map that keeps its entries ordered by key
+// according to a comparator, rather than by insertion order. It is backed by
+// a skip list, so lookups, inserts and deletes take O(log n) expected time.
+//
+// SortedMap has the same core methods as OrderedMap and implements Map, plus
+// ordered queries such as Floor, Ceiling and RangeFrom. Create SortedMaps
+// with NewSortedMap.
+type SortedMap struct {
+	mu     sync.RWMutex
+	cmp    func(a, b any) int
+	kind   orderedKind // Key kind enforced by the default comparator
+	head   *sortedNode // Sentinel whose next pointers start every level
+	tail   *sortedNode
+	level  int
+	length int
+}
+
+var _ Map = (*SortedMap)(nil)
+
+// NewSortedMap creates an empty SortedMap ordered by cmp, which must return
+// a negative number when a < b, a positive number when a > b and zero when
+// the keys are equal. Keys that compare equal are the same key.
+//
+// If cmp is nil, keys are compared in their natural order. All keys must
+// then be strings, all signed integers, all unsigned integers or all
+// floating-point numbers; Set returns an error for any other key.
+//
+// Example:
+//
+//	byVersion := NewSortedMap(func(a, b any) int {
+//	    return semver.Compare(a.(string), b.(string))
+//	})
+//	timestamps := NewSortedMap(nil)
+func NewSortedMap(cmp func(a, b any) int) *SortedMap {
+	sm := &SortedMap{cmp: cmp}
+	sm.reset()
+	return sm
+}
+
+// Set adds a new key-value pair or updates the value of an existing key.
+// Returns an error if the key is nil or not supported by the default
+// comparator. This method is thread-safe.
+//
+// Example:
+//
+//	sm.Set(3, "c")
+//	sm.Set(1, "a") // sm.Keys() == [1 3]
+func (sm *SortedMap) Set(key, value any) error {
+	if key == nil {
+		return fmt.Errorf("key cannot be nil")
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.cmp == nil {
+		kind := kindOf(key)
+		if kind == notOrdered {
+			return fmt.Errorf("key %v has unordered type %T", key, key)
+		}
+		if sm.length > 0 && kind != sm.kind {
+			return fmt.Errorf("key %v of type %T cannot be compared with the existing keys", key, key)
+		}
+		sm.kind = kind
+	}
+
+	var update [sortedMaxLevel]*sortedNode
+	x := sm.head
+	for i := sm.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && sm.compare(x.next[i].key, key) < 0 {
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+	if next := x.next[0]; next != nil && sm.compare(next.key, key) == 0 {
+		next.value = value
+		return nil
+	}
+
+	level := randomLevel()
+	if level > sm.level {
+		for i := sm.level; i < level; i++ {
+			update[i] = sm.head
+		}
+		sm.level = level
+	}
+
+	node := &sortedNode{key: key, value: value, next: make([]*sortedNode, level)}
+	for i := 0; i < level; i++ {
+		node.next[i] = update[i].next[i]
+		update[i].next[i] = node
+	}
+	if update[0] != sm.head {
+		node.prev = update[0]
+	}
+	if node.next[0] != nil {
+		node.next[0].prev = node
+	} else {
+		sm.tail = node
+	}
+	sm.length++
+	return nil
+}
+
+// Get retrieves the value associated with the given key.
+// This method is thread-safe.
+func (sm *SortedMap) Get(key any) (any, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if node := sm.find(key); node != nil {
+		return node.value, true
+	}
+	return nil, false
+}
+
+// Has checks if a key exists in the map. This method is thread-safe.
+func (sm *SortedMap) Has(key any) bool {
+	_, exists := sm.Get(key)
+	return exists
+}
+
+// Delete removes the element with the given key. Deleting a missing key is a
+// no-op. Returns an error if the key is nil. This method is thread-safe.
+func (sm *SortedMap) Delete(key any) error {
+	if key == nil {
+		return fmt.Errorf("key cannot be nil")
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if !sm.comparable(key) {
+		return nil
+	}
+	sm.delete(key)
+	return nil
+}
+
+// Len returns the number of elements in the map. This method is thread-safe.
+func (sm *SortedMap) Len() int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.length
+}
+
+// Clear removes all elements from the map. This method is thread-safe.
+func (sm *SortedMap) Clear() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.reset()
+}
+
+// Keys returns all keys in ascending order. This method is thread-safe.
+func (sm *SortedMap) Keys() []any {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	keys := make([]any, 0, sm.length)
+	for x := sm.head.next[0]; x != nil; x = x.next[0] {
+		keys = append(keys, x.key)
+	}
+	return keys
+}
+
+// Values returns all values in ascending order of their keys.
+// This method is thread-safe.
+func (sm *SortedMap) Values() []any {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	values := make([]any, 0, sm.length)
+	for x := sm.head.next[0]; x != nil; x = x.next[0] {
+		values = append(values, x.value)
+	}
+	return values
+}
+
+// Range calls f for each key-value pair in ascending key order. If f
+// returns false, iteration stops. f may modify the map; such changes don't
+// affect the ongoing iteration. This method is thread-safe.
+func (sm *SortedMap) Range(f func(key, value any) bool) {
+	sm.RangeFrom(nil, nil, f)
+}
+
+// RangeFrom calls f in ascending key order for each pair with
+// lo <= key < hi. A nil bound is unbounded. If f returns false, iteration
+// stops. f may modify the map. This method is thread-safe.
+//
+// Example:
+//
+//	// All events of January
+//	sm.RangeFrom(jan1, feb1, func(key, value any) bool {
+//	    fmt.Println(key, value)
+//	    return true
+//	})
+func (sm *SortedMap) RangeFrom(lo, hi any, f func(key, value any) bool) {
+	sm.mu.RLock()
+	type entry struct{ key, value any }
+	var entries []entry
+	x := sm.head.next[0]
+	if lo != nil {
+		x = nil
+		if sm.comparable(lo) {
+			x = sm.ceiling(lo, true)
+		}
+	}
+	if hi != nil && !sm.comparable(hi) {
+		x = nil
+	}
+	for ; x != nil; x = x.next[0] {
+		if hi != nil && sm.compare(x.key, hi) >= 0 {
+			break
+		}
+		entries = append(entries, entry{x.key, x.value})
+	}
+	sm.mu.RUnlock()
+
+	for _, e := range entries {
+		if !f(e.key, e.value) {
+			break
+		}
+	}
+}
+
+// First returns the pair with the smallest key. See Min.
+func (sm *SortedMap) First() (key, value any, exists bool) {
+	return sm.Min()
+}
+
+// Last returns the pair with the largest key. See Max.
+func (sm *SortedMap) Last() (key, value any, exists bool) {
+	return sm.Max()
+}
+
+// Min returns the pair with the smallest key, or false if the map is empty.
+// This method is thread-safe.
+func (sm *SortedMap) Min() (key, value any, exists bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return pairOf(sm.head.next[0])
+}
+
+// Max returns the pair with the largest key, or false if the map is empty.
+// This method is thread-safe.
+func (sm *SortedMap) Max() (key, value any, exists bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return pairOf(sm.tail)
+}
+
+// PopMin removes and returns the pair with the smallest key, or false if the
+// map is empty. This method is thread-safe.
+//
+// Example:
+//
+//	for {
+//	    at, job, ok := schedule.PopMin()
+//	    if !ok {
+//	        break
+//	    }
+//	    run(at, job)
+//	}
+func (sm *SortedMap) PopMin() (key, value any, exists bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	key, value, exists = pairOf(sm.head.next[0])
+	if exists {
+		sm.delete(key)
+	}
+	return key, value, exists
+}
+
+// PopMax removes and returns the pair with the largest key, or false if the
+// map is empty. This method is thread-safe.
+func (sm *SortedMap) PopMax() (key, value any, exists bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	key, value, exists = pairOf(sm.tail)
+	if exists {
+		sm.delete(key)
+	}
+	return key, value, exists
+}
+
+// Floor returns the pair with the largest key less than or equal to key.
+// This method is thread-safe.
+//
+// Example:
+//
+//	// The configuration in effect at time t
+//	_, config, ok := history.Floor(t)
+func (sm *SortedMap) Floor(key any) (k, v any, exists bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if !sm.comparable(key) {
+		return nil, nil, false
+	}
+	return pairOf(sm.before(sm.ceiling(key, false)))
+}
+
+// Ceiling returns the pair with the smallest key greater than or equal to
+// key. This method is thread-safe.
+func (sm *SortedMap) Ceiling(key any) (k, v any, exists bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if !sm.comparable(key) {
+		return nil, nil, false
+	}
+	return pairOf(sm.ceiling(key, true))
+}
+
+// Lower returns the pair with the largest key strictly less than key.
+// This method is thread-safe.
+func (sm *SortedMap) Lower(key any) (k, v any, exists bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if !sm.comparable(key) {
+		return nil, nil, false
+	}
+	return pairOf(sm.before(sm.ceiling(key, true)))
+}
+
+// Higher returns the pair with the smallest key strictly greater than key.
+// This method is thread-safe.
+func (sm *SortedMap) Higher(key any) (k, v any, exists bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if !sm.comparable(key) {
+		return nil, nil, false
+	}
+	return pairOf(sm.ceiling(key, false))
+}
+
+// String returns a string representation in the same format as
+// OrderedMap.String, in ascending key order. This method is thread-safe.
+func (sm *SortedMap) String() string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for x := sm.head.next[0]; x != nil; x = x.next[0] {
+		if x.prev != nil {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%v: %v", x.key, x.value)
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+// Copy returns a new SortedMap with the same comparator and contents.
+// This method is thread-safe.
+func (sm *SortedMap) Copy() *SortedMap {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	newMap := NewSortedMap(sm.cmp)
+	for x := sm.head.next[0]; x != nil; x = x.next[0] {
+		_ = newMap.Set(x.key, x.value)
+	}
+	return newMap
+}
+
+// MarshalJSON implements the json.Marshaler interface. Entries are written in
+// ascending key order, formatted like OrderedMap.MarshalJSON.
+// This method is thread-safe.
+func (sm *SortedMap) MarshalJSON() ([]byte, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for x := sm.head.next[0]; x != nil; x = x.next[0] {
+		if x.prev != nil {
+			buf.WriteByte(',')
+		}
+		if err := appendJSONEntry(&buf, x.key, x.value); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It replaces the
+// contents of the map with the members of a JSON object. Keys are strings,
+// so the comparator must accept string keys. If an error is returned, the
+// map is left unchanged. This method is thread-safe.
+func (sm *SortedMap) UnmarshalJSON(data []byte) error {
+	om := NewOrderedMap()
+	if err := om.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	// Build the new contents aside, so a failure leaves sm as it was. The
+	// comparator never changes, so it can be read without the lock.
+	restored := NewSortedMap(sm.cmp)
+	for current := om.head; current != nil; current = current.next {
+		if err := restored.Set(current.Key, current.Value); err != nil {
+			return err
+		}
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.head = restored.head
+	sm.tail = restored.tail
+	sm.level = restored.level
+	sm.length = restored.length
+	sm.kind = restored.kind
+	return nil
+}
+
+// compare applies the map's comparator.
+func (sm *SortedMap) compare(a, b any) int {
+	if sm.cmp == nil {
+		return compareOrdered(sm.kind, a, b)
+	}
+	return sm.cmp(a, b)
+}
+
+// comparable reports whether key can be compared with the keys in the map.
+func (sm *SortedMap) comparable(key any) bool {
+	if key == nil {
+		return false
+	}
+	return sm.cmp != nil || sm.length == 0 || kindOf(key) == sm.kind
+}
+
+// find returns the node for key, or nil.
+func (sm *SortedMap) find(key any) *sortedNode {
+	if !sm.comparable(key) || sm.length == 0 {
+		return nil
+	}
+	if x := sm.ceiling(key, true); x != nil && sm.compare(x.key, key) == 0 {
+		return x
+	}
+	return nil
+}
+
+// ceiling returns the first node whose key is >= key if inclusive, or > key
+// otherwise. It returns nil if there is none.
+func (sm *SortedMap) ceiling(key any, inclusive bool) *sortedNode {
+	if sm.length == 0 {
+		return nil
+	}
+	x := sm.head
+	for i := sm.level - 1; i >= 0; i-- {
+		for x.next[i] != nil {
+			c := sm.compare(x.next[i].key, key)
+			if c > 0 || (inclusive && c == 0) {
+				break
+			}
+			x = x.next[i]
+		}
+	}
+	return x.next[0]
+}
+
+// before returns the node preceding x, or the last node if x is nil.
+func (sm *SortedMap) before(x *sortedNode) *sortedNode {
+	if x == nil {
+		return sm.tail
+	}
+	return x.prev
+}
+
+// delete unlinks the node for key if it exists. The caller must hold sm.mu
+// for writing.
+func (sm *SortedMap) delete(key any) {
+	var update [sortedMaxLevel]*sortedNode
+	x := sm.head
+	for i := sm.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && sm.compare(x.next[i].key, key) < 0 {
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+	node := x.next[0]
+	if node == nil || sm.compare(node.key, key) != 0 {
+		return
+	}
+
+	for i := range node.next {
+		update[i].next[i] = node.next[i]
+	}
+	if node.next[0] != nil {
+		node.next[0].prev = node.prev
+	} else {
+		sm.tail = node.prev
+	}
+	for sm.level > 1 && sm.head.next[sm.level-1] == nil {
+		sm.level--
+	}
+	sm.length--
+}
+
+func (sm *SortedMap) reset() {
+	sm.head = &sortedNode{next: make([]*sortedNode, sortedMaxLevel)}
+	sm.tail = nil
+	sm.level = 1
+	sm.length = 0
+	sm.kind = notOrdered
+}
+
+func pairOf(x *sortedNode) (key, value any, exists bool) {
+	if x == nil {
+		return nil, nil, false
+	}
+	return x.key, x.value, true
+}
+
+// randomLevel picks the number of levels for a new node.
+func randomLevel() int {
+	level := 1
+	for level < sortedMaxLevel && rand.IntN(sortedP) == 0 {
+		level++
+	}
+	return level
+}
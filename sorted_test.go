@@ -0,0 +1,282 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSortedMap(t *testing.T) {
+	sm := NewSortedMap(nil)
+	for _, k := range []int{50, 10, 40, 20, 30} {
+		if err := sm.Set(k, k*2); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("Ordered By Key", func(t *testing.T) {
+		if !reflect.DeepEqual(sm.Keys(), []any{10, 20, 30, 40, 50}) {
+			t.Errorf("Unexpected keys %v", sm.Keys())
+		}
+		if !reflect.DeepEqual(sm.Values(), []any{20, 40, 60, 80, 100}) {
+			t.Errorf("Unexpected values %v", sm.Values())
+		}
+		sm.Set(30, "updated")
+		if sm.Len() != 5 {
+			t.Errorf("Expected update not to add a key, got %d", sm.Len())
+		}
+		if v, ok := sm.Get(30); !ok || v != "updated" {
+			t.Errorf("Expected updated value, got %v", v)
+		}
+		if sm.String() != "{10: 20, 20: 40, 30: updated, 40: 80, 50: 100}" {
+			t.Errorf("Unexpected String %s", sm.String())
+		}
+	})
+
+	t.Run("Neighbors", func(t *testing.T) {
+		tests := []struct {
+			name string
+			fn   func(key any) (any, any, bool)
+			key  int
+			want any
+		}{
+			{"Floor exact", sm.Floor, 20, 20},
+			{"Floor between", sm.Floor, 25, 20},
+			{"Floor below", sm.Floor, 5, nil},
+			{"Ceiling exact", sm.Ceiling, 20, 20},
+			{"Ceiling between", sm.Ceiling, 25, 30},
+			{"Ceiling above", sm.Ceiling, 55, nil},
+			{"Lower exact", sm.Lower, 20, 10},
+			{"Lower first", sm.Lower, 10, nil},
+			{"Lower above", sm.Lower, 99, 50},
+			{"Higher exact", sm.Higher, 20, 30},
+			{"Higher last", sm.Higher, 50, nil},
+			{"Higher below", sm.Higher, 0, 10},
+		}
+		for _, tt := range tests {
+			k, _, ok := tt.fn(tt.key)
+			if ok != (tt.want != nil) || k != tt.want {
+				t.Errorf("%s(%d): expected %v, got %v, %v", tt.name, tt.key, tt.want, k, ok)
+			}
+		}
+		if _, _, ok := sm.Floor("string"); ok {
+			t.Error("Expected a key of another type not to match")
+		}
+	})
+
+	t.Run("RangeFrom", func(t *testing.T) {
+		collect := func(lo, hi any) []any {
+			var keys []any
+			sm.RangeFrom(lo, hi, func(key, value any) bool {
+				keys = append(keys, key)
+				return true
+			})
+			return keys
+		}
+		if got := collect(20, 40); !reflect.DeepEqual(got, []any{20, 30}) {
+			t.Errorf("Expected [20 30], got %v", got)
+		}
+		if got := collect(15, nil); !reflect.DeepEqual(got, []any{20, 30, 40, 50}) {
+			t.Errorf("Expected [20 30 40 50], got %v", got)
+		}
+		if got := collect(nil, 30); !reflect.DeepEqual(got, []any{10, 20}) {
+			t.Errorf("Expected [10 20], got %v", got)
+		}
+		if got := collect(40, 20); len(got) != 0 {
+			t.Errorf("Expected empty range, got %v", got)
+		}
+	})
+
+	t.Run("Min Max Pop", func(t *testing.T) {
+		sm := sm.Copy()
+		if k, _, _ := sm.Min(); k != 10 {
+			t.Errorf("Expected min 10, got %v", k)
+		}
+		if k, _, _ := sm.Max(); k != 50 {
+			t.Errorf("Expected max 50, got %v", k)
+		}
+		if k, v, ok := sm.PopMin(); !ok || k != 10 || v != 20 {
+			t.Errorf("Unexpected PopMin (%v, %v, %v)", k, v, ok)
+		}
+		if k, _, ok := sm.PopMax(); !ok || k != 50 {
+			t.Errorf("Unexpected PopMax (%v, %v)", k, ok)
+		}
+		if !reflect.DeepEqual(sm.Keys(), []any{20, 30, 40}) {
+			t.Errorf("Unexpected keys %v", sm.Keys())
+		}
+		if k, _, _ := sm.Last(); k != 40 {
+			t.Errorf("Expected last 40 after PopMax, got %v", k)
+		}
+		sm.Clear()
+		if _, _, ok := sm.PopMin(); ok {
+			t.Error("Expected PopMin on empty map to fail")
+		}
+		if _, _, ok := sm.PopMax(); ok {
+			t.Error("Expected PopMax on empty map to fail")
+		}
+	})
+
+	t.Run("Key Validation", func(t *testing.T) {
+		if err := sm.Set(nil, 1); err == nil {
+			t.Error("Expected error for nil key")
+		}
+		if err := sm.Set("x", 1); err == nil {
+			t.Error("Expected error for mixed key types")
+		}
+		if err := NewSortedMap(nil).Set([2]int{}, 1); err == nil {
+			t.Error("Expected error for unordered key type")
+		}
+		if err := sm.Delete("x"); err != nil || sm.Has("x") {
+			t.Error("Expected deleting a key of another type to be a no-op")
+		}
+	})
+}
+
+func TestSortedMap_Comparator(t *testing.T) {
+	// Case-insensitive keys, longest first.
+	sm := NewSortedMap(func(a, b any) int {
+		x, y := strings.ToLower(a.(string)), strings.ToLower(b.(string))
+		if len(x) != len(y) {
+			return len(y) - len(x)
+		}
+		return strings.Compare(x, y)
+	})
+	sm.Set("bb", 1)
+	sm.Set("a", 2)
+	sm.Set("CCC", 3)
+	sm.Set("BB", 4)
+
+	if !reflect.DeepEqual(sm.Keys(), []any{"CCC", "bb", "a"}) {
+		t.Errorf("Unexpected keys %v", sm.Keys())
+	}
+	if v, _ := sm.Get("Bb"); v != 4 {
+		t.Errorf("Expected equal keys to share an entry, got %v", v)
+	}
+}
+
+func TestSortedMap_JSON(t *testing.T) {
+	sm := NewSortedMap(nil)
+	sm.Set("zeta", 1)
+	sm.Set("alpha", []any{1, 2})
+	sm.Set("mid", map[string]any{"x": true})
+
+	data, err := json.Marshal(sm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"alpha":[1,2],"mid":{"x":true},"zeta":1}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	var decoded SortedMap
+	if err := json.Unmarshal([]byte(`{"b":1,"a":2,"c":3}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Keys(), []any{"a", "b", "c"}) {
+		t.Errorf("Unexpected keys %v", decoded.Keys())
+	}
+	if err := decoded.UnmarshalJSON([]byte(`[1]`)); err == nil {
+		t.Error("Expected error for a JSON array")
+	}
+	if err := decoded.UnmarshalJSON([]byte(`{"x":1,"y":}`)); err == nil {
+		t.Error("Expected error for malformed JSON")
+	}
+	if !reflect.DeepEqual(decoded.Keys(), []any{"a", "b", "c"}) {
+		t.Errorf("Expected a failed decode to leave the map unchanged, got %v", decoded.Keys())
+	}
+
+	t.Run("Concurrent Readers", func(t *testing.T) {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				decoded.Len()
+				decoded.Keys()
+			}
+		}()
+		for i := 0; i < 100; i++ {
+			if err := decoded.UnmarshalJSON([]byte(`{"b":1,"a":2}`)); err != nil {
+				t.Error(err)
+			}
+		}
+		wg.Wait()
+		if decoded.Len() != 2 {
+			t.Errorf("Expected 2 entries, got %d", decoded.Len())
+		}
+	})
+}
+
+func TestSortedMap_Random(t *testing.T) {
+	sm := NewSortedMap(nil)
+	reference := map[int]int{}
+	for i := 0; i < 2000; i++ {
+		k := rand.IntN(500)
+		if rand.IntN(3) == 0 {
+			sm.Delete(k)
+			delete(reference, k)
+		} else {
+			sm.Set(k, i)
+			reference[k] = i
+		}
+	}
+
+	keys := make([]int, 0, len(reference))
+	for k := range reference {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	if sm.Len() != len(keys) {
+		t.Fatalf("Expected %d entries, got %d", len(keys), sm.Len())
+	}
+	got := sm.Keys()
+	for i, k := range keys {
+		if got[i] != k {
+			t.Fatalf("Expected key %d at %d, got %v", k, i, got[i])
+		}
+		if v, _ := sm.Get(k); v != reference[k] {
+			t.Fatalf("Expected %d=%d, got %v", k, reference[k], v)
+		}
+	}
+
+	// Walking backwards through Lower must visit the same keys.
+	var backward []int
+	k, _, ok := sm.Max()
+	for ok {
+		backward = append(backward, k.(int))
+		k, _, ok = sm.Lower(k)
+	}
+	slices.Reverse(backward)
+	if !slices.Equal(backward, keys) {
+		t.Error("Backward walk doesn't match the sorted keys")
+	}
+}
+
+func TestSortedMap_Concurrent(t *testing.T) {
+	sm := NewSortedMap(nil)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(base int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				sm.Set(base*1000+i, i)
+				sm.Get(i)
+				sm.Floor(i)
+				if i%3 == 0 {
+					sm.PopMin()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if !slices.IsSortedFunc(sm.Keys(), func(a, b any) int { return a.(int) - b.(int) }) {
+		t.Error("Expected keys to stay sorted")
+	}
+}
@@ -0,0 +1,133 @@
+package orderedmap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// textLayout remembers how a parsed text file looked, so that writing the
+// map back reproduces the file except where entries were changed. Only the
+// parser builds a layout; afterwards it is never modified, so maps may
+// share it.
+type textLayout struct {
+	format  string               // "env", "properties", "ini" or "query"
+	entries map[any]*layoutEntry // Per key, as stored in the map
+	trailer []string             // Comment and blank lines after the last entry
+	text    string               // The whole input, for single-line formats
+}
+
+// layoutEntry is the text of one entry and the lines above it.
+type layoutEntry struct {
+	before  []string // Comment and blank lines directly above the entry
+	raw     []string // The entry's original lines
+	value   any      // The parsed value, to tell whether it was changed
+	head    string   // Text before the value: indentation, key and separator
+	comment string   // Text after the value, such as an inline comment
+	index   int      // Position of the key among the keys of the file
+}
+
+func newTextLayout(format string) *textLayout {
+	return &textLayout{format: format, entries: make(map[any]*layoutEntry)}
+}
+
+// add records the entry for key. If the key was seen before, the map keeps
+// its first position, so the earlier entry takes the new text and the lines
+// above the later one.
+func (l *textLayout) add(key any, e *layoutEntry) {
+	if prev, exists := l.entries[key]; exists {
+		prev.before = append(prev.before, e.before...)
+		prev.raw, prev.value, prev.head, prev.comment = e.raw, e.value, e.head, e.comment
+		return
+	}
+	l.entries[key] = e
+}
+
+// splitLines splits the input into lines without their line endings.
+func splitLines(r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines, nil
+}
+
+// writeText writes the entries of the map in order, one or more lines each.
+// If the map was parsed from a file of the same format, the comment and
+// blank lines of the file are kept, and entries whose value has not changed
+// are written exactly as they were read. Other entries are formatted by
+// encode, which receives the entry's layout or nil for new keys.
+func (om *OrderedMap) writeText(w io.Writer, format string, encode func(e *layoutEntry, key, value any) (string, error)) error {
+	om.rlock()
+	defer om.runlock()
+
+	bw := bufio.NewWriter(w)
+	if err := om.writeEntries(bw, om.layoutFor(format), encode); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// layoutFor returns the layout of the map if it was parsed from a file of
+// the given format, or nil. The caller must hold om.mu.
+func (om *OrderedMap) layoutFor(format string) *textLayout {
+	if om.layout != nil && om.layout.format == format {
+		return om.layout
+	}
+	return nil
+}
+
+// writeEntries is the body of writeText. layout may be nil. The caller must
+// hold om.mu.
+func (om *OrderedMap) writeEntries(bw *bufio.Writer, layout *textLayout, encode func(e *layoutEntry, key, value any) (string, error)) error {
+	for current := om.head; current != nil; current = current.next {
+		var e *layoutEntry
+		if layout != nil {
+			e = layout.entries[current.Key]
+		}
+		if e != nil {
+			writeLines(bw, e.before)
+			if valuesEqual(current.Value, e.value) {
+				writeLines(bw, e.raw)
+				continue
+			}
+		}
+		line, err := encode(e, current.Key, current.Value)
+		if err != nil {
+			return err
+		}
+		writeLines(bw, []string{line})
+	}
+	if layout != nil {
+		writeLines(bw, layout.trailer)
+	}
+	return nil
+}
+
+func writeLines(bw *bufio.Writer, lines []string) {
+	for _, line := range lines {
+		bw.WriteString(line)
+		bw.WriteByte('\n')
+	}
+}
+
+// textValue formats a value for a text file. Nil is written as an empty
+// string.
+func textValue(v any) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,337 @@
+package orderedmap
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTxDone is returned by operations on a transaction that has already been
+// committed or rolled back.
+var ErrTxDone = errors.New("transaction has already been committed or rolled back")
+
+// ReadTx is a read-only transaction passed to View. All reads inside one
+// ReadTx observe the same state of the map.
+//
+// A ReadTx is only valid until the function it was passed to returns, and it
+// must not be used from other goroutines. Methods of the underlying map must
+// not be called while the transaction is open.
+type ReadTx struct {
+	om *OrderedMap
+}
+
+// Tx is a read-write transaction passed to Update or returned by Begin.
+//
+// Changes made through a Tx are applied in place while the map's write lock is
+// held, so other goroutines never observe a partially applied transaction.
+// Reads through the Tx see its own uncommitted changes. Change events and
+// waiters are only notified on commit; a rollback undoes every change,
+// including order changes, and emits nothing.
+type Tx struct {
+	ReadTx
+	undo   []func()
+	events []Event
+	done   bool
+}
+
+// View runs fn in a read-only transaction. The map's read lock is held for
+// the duration of fn, so fn sees a consistent state. The error returned by fn
+// is returned by View.
+//
+// Example:
+//
+//	err := om.View(func(tx *ReadTx) error {
+//	    a, _ := tx.Get("a")
+//	    b, _ := tx.Get("b")
+//	    fmt.Println(a, b)
+//	    return nil
+//	})
+func (om *OrderedMap) View(fn func(tx *ReadTx) error) error {
+	om.rlock()
+	defer om.runlock()
+	return fn(&ReadTx{om: om})
+}
+
+// Update runs fn in a read-write transaction. If fn returns nil, all of its
+// changes become visible at once; if it returns an error or panics, every
+// change is rolled back and the error is returned (or the panic re-raised).
+// If fn ends the transaction itself with Commit or Rollback and returns nil,
+// Update returns nil.
+//
+// Example:
+//
+//	err := om.Update(func(tx *Tx) error {
+//	    balance, _ := tx.Get("alice")
+//	    if balance.(int) < 10 {
+//	        return errors.New("insufficient funds")
+//	    }
+//	    tx.Set("alice", balance.(int)-10)
+//	    tx.Set("bob", 10)
+//	    tx.MoveToFront("bob")
+//	    return nil
+//	})
+func (om *OrderedMap) Update(fn func(tx *Tx) error) error {
+	tx := om.Begin()
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, ErrTxDone) {
+			return rbErr
+		}
+		return err
+	}
+	if tx.done {
+		// fn ended the transaction itself.
+		return nil
+	}
+	return tx.Commit()
+}
+
+// Begin starts a read-write transaction and acquires the map's write lock.
+// The caller must end the transaction with Commit or Rollback; until then all
+// other access to the map blocks. Prefer Update, which does this
+// automatically.
+//
+// Example:
+//
+//	tx := om.Begin()
+//	tx.Set("a", 1)
+//	tx.Delete("b")
+//	if err := tx.Commit(); err != nil {
+//	    log.Fatal(err)
+//	}
+func (om *OrderedMap) Begin() *Tx {
+	om.lock()
+	tx := &Tx{ReadTx: ReadTx{om: om}}
+	om.tx = tx
+	return tx
+}
+
+// Commit makes the transaction's changes visible, delivers its change events
+// and releases the write lock.
+func (tx *Tx) Commit() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.done = true
+	om := tx.om
+	om.tx = nil
+	for _, ev := range tx.events {
+		om.emit(ev)
+	}
+	tx.undo = nil
+	tx.events = nil
+	om.unlock()
+	return nil
+}
+
+// Rollback discards every change made in the transaction and releases the
+// write lock.
+func (tx *Tx) Rollback() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.done = true
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+	tx.undo = nil
+	tx.events = nil
+	tx.om.tx = nil
+	tx.om.unlock()
+	return nil
+}
+
+// Set adds or updates a key-value pair within the transaction.
+// Returns an error if the key is nil or the transaction is finished.
+func (tx *Tx) Set(key, value any) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	if key == nil {
+		return fmt.Errorf("key cannot be nil")
+	}
+
+	om := tx.om
+	key = om.normalizeKey(key)
+	if node, exists := om.nodeMap[key]; exists {
+		old := node.Value
+		tx.undo = append(tx.undo, func() { node.Value = old })
+	} else {
+		if om.maxLen > 0 && om.length >= om.maxLen {
+			// The insert evicts entries from the front; bring them back first.
+			var evicted []*Node
+			for node := om.head; node != nil && om.length-len(evicted) >= om.maxLen; node = node.next {
+				evicted = append(evicted, node)
+			}
+			tx.undo = append(tx.undo, func() {
+				for i := len(evicted) - 1; i >= 0; i-- {
+					om.pushFront(evicted[i])
+					om.nodeMap[evicted[i].Key] = evicted[i]
+					om.length++
+				}
+			})
+		}
+		tx.undo = append(tx.undo, func() {
+			node := om.nodeMap[key]
+			om.unlink(node)
+			delete(om.nodeMap, key)
+			om.length--
+		})
+	}
+	return om.setNormalized(key, value)
+}
+
+// Delete removes a key within the transaction. Deleting a missing key is a
+// no-op. Returns an error if the key is nil or the transaction is finished.
+func (tx *Tx) Delete(key any) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	if key == nil {
+		return fmt.Errorf("key cannot be nil")
+	}
+
+	om := tx.om
+	node, exists := om.lookup(key)
+	if !exists {
+		return nil
+	}
+	prev := node.prev
+	tx.undo = append(tx.undo, func() {
+		om.insertAfter(node, prev)
+		om.nodeMap[node.Key] = node
+		om.length++
+	})
+	om.remove(node)
+	return nil
+}
+
+// Clear removes all elements within the transaction.
+func (tx *Tx) Clear() error {
+	if tx.done {
+		return ErrTxDone
+	}
+
+	om := tx.om
+	head, tail, nodeMap, length, layout := om.head, om.tail, om.nodeMap, om.length, om.layout
+	seq, cleared := om.seq, om.cleared
+	tx.undo = append(tx.undo, func() {
+		om.head, om.tail, om.nodeMap, om.length, om.layout = head, tail, nodeMap, length, layout
+		// Cursors on the restored nodes must not take them for cleared.
+		om.seq, om.cleared = seq, cleared
+	})
+	om.reset()
+	return nil
+}
+
+// MoveToFront moves an existing key to the front of the map within the
+// transaction. Returns false if the key is nil, missing, or the transaction
+// is finished.
+func (tx *Tx) MoveToFront(key any) bool {
+	return tx.move(key, func(*Node) *Node { return nil })
+}
+
+// MoveToBack moves an existing key to the back of the map within the
+// transaction. Returns false if the key is nil, missing, or the transaction
+// is finished.
+func (tx *Tx) MoveToBack(key any) bool {
+	return tx.move(key, func(*Node) *Node { return tx.om.tail })
+}
+
+func (tx *Tx) move(key any, target func(node *Node) *Node) bool {
+	if tx.done || key == nil {
+		return false
+	}
+
+	om := tx.om
+	node, exists := om.lookup(key)
+	if !exists {
+		return false
+	}
+	prev := node.prev
+	tx.undo = append(tx.undo, func() {
+		om.unlink(node)
+		om.insertAfter(node, prev)
+	})
+	om.moveAfter(node, target(node))
+	return true
+}
+
+// Get retrieves the value associated with the given key.
+func (tx *ReadTx) Get(key any) (any, bool) {
+	if node, exists := tx.om.lookup(key); exists {
+		return node.Value, true
+	}
+	return nil, false
+}
+
+// Has checks if a key exists.
+func (tx *ReadTx) Has(key any) bool {
+	_, exists := tx.Get(key)
+	return exists
+}
+
+// Len returns the number of elements.
+func (tx *ReadTx) Len() int {
+	return tx.om.length
+}
+
+// Keys returns all keys in order.
+func (tx *ReadTx) Keys() []any {
+	keys := make([]any, 0, tx.om.length)
+	for current := tx.om.head; current != nil; current = current.next {
+		keys = append(keys, current.Key)
+	}
+	return keys
+}
+
+// Values returns all values in order.
+func (tx *ReadTx) Values() []any {
+	values := make([]any, 0, tx.om.length)
+	for current := tx.om.head; current != nil; current = current.next {
+		values = append(values, current.Value)
+	}
+	return values
+}
+
+// Range iterates over the map in order and calls f for each key-value pair.
+// If f returns false, iteration stops. f may modify the map through a Tx;
+// such changes don't affect the ongoing iteration.
+func (tx *ReadTx) Range(f func(key, value any) bool) {
+	type entry struct{ key, value any }
+	entries := make([]entry, 0, tx.om.length)
+	for current := tx.om.head; current != nil; current = current.next {
+		entries = append(entries, entry{current.Key, current.Value})
+	}
+	for _, e := range entries {
+		if !f(e.key, e.value) {
+			break
+		}
+	}
+}
+
+// First returns the first key-value pair.
+func (tx *ReadTx) First() (key, value any, exists bool) {
+	if tx.om.head == nil {
+		return nil, nil, false
+	}
+	return tx.om.head.Key, tx.om.head.Value, true
+}
+
+// Last returns the last key-value pair.
+func (tx *ReadTx) Last() (key, value any, exists bool) {
+	if tx.om.tail == nil {
+		return nil, nil, false
+	}
+	return tx.om.tail.Key, tx.om.tail.Value, true
+}
+
+// String returns a string representation in the same format as OrderedMap.String.
+func (tx *ReadTx) String() string {
+	return formatNodes(tx.om.head)
+}
@@ -0,0 +1,295 @@
+package orderedmap
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestOrderedMap_Update(t *testing.T) {
+	t.Run("Commit", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set("a", 1)
+		om.Set("b", 2)
+
+		err := om.Update(func(tx *Tx) error {
+			tx.Set("a", 10)
+			tx.Set("c", 3)
+			tx.Delete("b")
+			tx.MoveToFront("c")
+			if v, _ := tx.Get("a"); v != 10 {
+				t.Errorf("Expected tx to read its own write, got %v", v)
+			}
+			if tx.Has("b") || tx.Len() != 2 {
+				t.Error("Expected tx to see its own delete")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if om.String() != "{c: 3, a: 10}" {
+			t.Errorf("Unexpected state after commit: %s", om.String())
+		}
+	})
+
+	t.Run("Rollback Restores Everything", func(t *testing.T) {
+		om := NewOrderedMap()
+		for _, k := range []string{"a", "b", "c", "d"} {
+			om.Set(k, k)
+		}
+		before := om.String()
+		errAbort := errors.New("abort")
+
+		err := om.Update(func(tx *Tx) error {
+			tx.Set("a", "changed")
+			tx.Set("e", "new")
+			tx.Delete("b")
+			tx.MoveToFront("d")
+			tx.MoveToBack("a")
+			tx.Delete("c")
+			tx.Set("b", "again")
+			tx.Clear()
+			tx.Set("only", 1)
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Errorf("Expected abort error, got %v", err)
+		}
+		if om.String() != before {
+			t.Errorf("Expected %s after rollback, got %s", before, om.String())
+		}
+		if k, _, _ := om.Last(); k != "d" {
+			t.Errorf("Expected tail d after rollback, got %v", k)
+		}
+		if om.Len() != 4 || len(om.nodeMap) != 4 {
+			t.Errorf("Expected 4 elements after rollback, got %d", om.Len())
+		}
+
+		// The map must remain fully usable.
+		om.Delete("b")
+		om.Set("z", 26)
+		if om.String() != "{a: a, c: c, d: d, z: 26}" {
+			t.Errorf("Unexpected state %s", om.String())
+		}
+	})
+
+	t.Run("Panic Rolls Back", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set("a", 1)
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic to propagate")
+				}
+			}()
+			om.Update(func(tx *Tx) error {
+				tx.Set("a", 2)
+				panic("boom")
+			})
+		}()
+		if v, _ := om.Get("a"); v != 1 {
+			t.Errorf("Expected a=1 after panic, got %v", v)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		om := NewOrderedMap()
+		var leaked *Tx
+		om.Update(func(tx *Tx) error {
+			if err := tx.Set(nil, 1); err == nil {
+				t.Error("Expected error for nil key")
+			}
+			if err := tx.Delete(nil); err == nil {
+				t.Error("Expected error for nil key")
+			}
+			if tx.MoveToFront("missing") {
+				t.Error("Expected false for missing key")
+			}
+			leaked = tx
+			return nil
+		})
+		if err := leaked.Set("a", 1); !errors.Is(err, ErrTxDone) {
+			t.Errorf("Expected ErrTxDone, got %v", err)
+		}
+		if err := leaked.Commit(); !errors.Is(err, ErrTxDone) {
+			t.Errorf("Expected ErrTxDone, got %v", err)
+		}
+		if err := leaked.Clear(); !errors.Is(err, ErrTxDone) {
+			t.Errorf("Expected ErrTxDone, got %v", err)
+		}
+	})
+
+	t.Run("Committed By fn", func(t *testing.T) {
+		om := NewOrderedMap()
+		err := om.Update(func(tx *Tx) error {
+			tx.Set("a", 1)
+			return tx.Commit()
+		})
+		if err != nil {
+			t.Errorf("Expected success, got %v", err)
+		}
+		if !om.Has("a") {
+			t.Error("Expected the commit to be kept")
+		}
+	})
+
+	t.Run("Cleared Then Rolled Back Keeps Cursors", func(t *testing.T) {
+		om := NewOrderedMap()
+		for _, k := range []string{"a", "b", "c"} {
+			om.Set(k, k)
+		}
+		c := om.Cursor()
+		c.Seek("b")
+		om.Delete("b")
+		om.Update(func(tx *Tx) error {
+			tx.Clear()
+			return errors.New("rollback")
+		})
+		if !c.Next() || c.Key() != "c" {
+			t.Errorf("Expected Next to reach c, got %v", c.Key())
+		}
+	})
+}
+
+func TestOrderedMap_TxEvents(t *testing.T) {
+	om := NewOrderedMap()
+	om.Set("a", 1)
+	var events []EventType
+	om.OnChange(func(ev Event) { events = append(events, ev.Type) })
+
+	om.Update(func(tx *Tx) error {
+		tx.Set("b", 2)
+		tx.Delete("a")
+		if len(events) != 0 {
+			t.Error("Expected events to be held back until commit")
+		}
+		return errors.New("rollback")
+	})
+	if len(events) != 0 {
+		t.Errorf("Expected no events after rollback, got %v", events)
+	}
+
+	om.Update(func(tx *Tx) error {
+		tx.Set("b", 2)
+		tx.MoveToFront("b")
+		return nil
+	})
+	expected := []EventType{EventInserted, EventMoved}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected %v after commit, got %v", expected, events)
+	}
+}
+
+func TestOrderedMap_BeginCommitRollback(t *testing.T) {
+	om := NewOrderedMap()
+
+	tx := om.Begin()
+	tx.Set("a", 1)
+	tx.Set("b", 2)
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	tx = om.Begin()
+	tx.MoveToBack("a")
+	tx.Set("c", 3)
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); !errors.Is(err, ErrTxDone) {
+		t.Errorf("Expected ErrTxDone, got %v", err)
+	}
+	if om.String() != "{a: 1, b: 2}" {
+		t.Errorf("Unexpected state %s", om.String())
+	}
+}
+
+func TestOrderedMap_TxWithSnapshot(t *testing.T) {
+	om := NewOrderedMap()
+	om.Set("a", 1)
+	om.Set("b", 2)
+	snap := om.Snapshot()
+
+	om.Update(func(tx *Tx) error {
+		tx.Clear()
+		return errors.New("rollback")
+	})
+	om.Set("a", 100)
+	if v, _ := snap.Get("a"); v != 1 {
+		t.Errorf("Expected snapshot to keep a=1, got %v", v)
+	}
+}
+
+func TestOrderedMap_View(t *testing.T) {
+	om := NewOrderedMap()
+	om.Set("x", 1)
+	om.Set("y", 2)
+
+	err := om.View(func(tx *ReadTx) error {
+		if tx.Len() != 2 || !tx.Has("x") {
+			t.Error("Unexpected contents in view")
+		}
+		if !reflect.DeepEqual(tx.Keys(), []any{"x", "y"}) || !reflect.DeepEqual(tx.Values(), []any{1, 2}) {
+			t.Error("Unexpected keys or values in view")
+		}
+		if k, _, _ := tx.First(); k != "x" {
+			t.Errorf("Expected first x, got %v", k)
+		}
+		if k, _, _ := tx.Last(); k != "y" {
+			t.Errorf("Expected last y, got %v", k)
+		}
+		var seen []any
+		tx.Range(func(key, value any) bool {
+			seen = append(seen, key)
+			return false
+		})
+		if len(seen) != 1 {
+			t.Errorf("Expected Range to stop early, got %v", seen)
+		}
+		if tx.String() != "{x: 1, y: 2}" {
+			t.Errorf("Unexpected String %s", tx.String())
+		}
+		return errors.New("view error")
+	})
+	if err == nil || err.Error() != "view error" {
+		t.Errorf("Expected view error, got %v", err)
+	}
+}
+
+func TestOrderedMap_TxAtomicity(t *testing.T) {
+	om := NewOrderedMap()
+	om.Set("left", 100)
+	om.Set("right", 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			om.Update(func(tx *Tx) error {
+				l, _ := tx.Get("left")
+				r, _ := tx.Get("right")
+				tx.Set("left", l.(int)-1)
+				tx.Set("right", r.(int)+1)
+				return nil
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			om.View(func(tx *ReadTx) error {
+				l, _ := tx.Get("left")
+				r, _ := tx.Get("right")
+				if l.(int)+r.(int) != 100 {
+					t.Errorf("Observed half-applied transaction: %v + %v", l, r)
+				}
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	if v, _ := om.Get("right"); v != 50 {
+		t.Errorf("Expected right=50, got %v", v)
+	}
+}
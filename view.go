@@ -0,0 +1,223 @@
+package orderedmap
+
+import "sync"
+
+// Snapshot is a read-only, point-in-time view of an OrderedMap.
+//
+// Taking a snapshot is O(1): the snapshot shares the live map's nodes until
+// the live map is next written. The first write after one or more snapshots
+// copies all entries into them (O(n)) before modifying the live map. The
+// copy runs under the live map's write lock and excludes the snapshot's
+// readers, so while it runs neither the live map nor the snapshot can be
+// read. Later writes cost nothing extra until another snapshot is taken.
+//
+// A snapshot therefore pays off when it is followed by several writes, or by
+// none. If snapshots and writes alternate, every write pays the O(n) copy,
+// which costs about as much as Copy and also blocks readers while it runs
+// (see BenchmarkSnapshotThenSet and BenchmarkCopyThenSet).
+//
+// Apart from that copy, Snapshot methods never take the live map's lock, so
+// readers of a snapshot don't contend with the live map's readers or
+// writers. A Snapshot is safe for concurrent use.
+type Snapshot struct {
+	state *frozenState
+}
+
+// frozenState is the data seen by snapshots. Until it is detached it aliases
+// the live map's list and index; detaching replaces them with private copies.
+type frozenState struct {
+	mu      sync.RWMutex // Excludes readers while the state is being detached
+	head    *Node
+	tail    *Node
+	nodeMap map[any]*Node
+	length  int
+
+	normalize func(key any) any // The map's key normalizer, if any
+	maxLen    int               // The map's length limit, if any
+}
+
+// Snapshot returns a read-only view of the map as it is now. Later changes
+// to the map are not visible through the snapshot.
+// This method is thread-safe.
+//
+// Example:
+//
+//	snap := om.Snapshot()
+//	om.Set("new", 1)
+//	snap.Has("new") // false
+func (om *OrderedMap) Snapshot() *Snapshot {
+	om.lock()
+	defer om.unlock()
+
+	if om.frozen == nil {
+		om.frozen = &frozenState{
+			head:      om.head,
+			tail:      om.tail,
+			nodeMap:   om.nodeMap,
+			length:    om.length,
+			normalize: om.normalize,
+			maxLen:    om.maxLen,
+		}
+	}
+	return &Snapshot{state: om.frozen}
+}
+
+// prepareWrite detaches snapshots that still share the map's nodes. Every
+// internal mutation must call it before modifying a node, the list or the
+// node index. The caller must hold om.mu for writing.
+func (om *OrderedMap) prepareWrite() {
+	if om.frozen != nil {
+		om.frozen.detach()
+		om.frozen = nil
+	}
+}
+
+// detach gives the state its own copy of the nodes it currently aliases.
+func (s *frozenState) detach() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodeMap := make(map[any]*Node, s.length)
+	var head, tail *Node
+	for current := s.head; current != nil; current = current.next {
+		node := &Node{Key: current.Key, Value: current.Value, prev: tail}
+		if tail == nil {
+			head = node
+		} else {
+			tail.next = node
+		}
+		tail = node
+		nodeMap[node.Key] = node
+	}
+	s.head = head
+	s.tail = tail
+	s.nodeMap = nodeMap
+}
+
+// Get retrieves the value associated with the given key at the time the
+// snapshot was taken.
+func (s *Snapshot) Get(key any) (any, bool) {
+	if key == nil {
+		return nil, false
+	}
+
+	s.state.mu.RLock()
+	defer s.state.mu.RUnlock()
+
+	if s.state.normalize != nil {
+		key = s.state.normalize(key)
+	}
+	if node, exists := s.state.nodeMap[key]; exists {
+		return node.Value, true
+	}
+	return nil, false
+}
+
+// Has checks if a key existed when the snapshot was taken.
+func (s *Snapshot) Has(key any) bool {
+	_, exists := s.Get(key)
+	return exists
+}
+
+// Len returns the number of elements in the snapshot.
+func (s *Snapshot) Len() int {
+	return s.state.length
+}
+
+// Keys returns the keys of the snapshot in order.
+func (s *Snapshot) Keys() []any {
+	s.state.mu.RLock()
+	defer s.state.mu.RUnlock()
+
+	keys := make([]any, 0, s.state.length)
+	for current := s.state.head; current != nil; current = current.next {
+		keys = append(keys, current.Key)
+	}
+	return keys
+}
+
+// Values returns the values of the snapshot in order.
+func (s *Snapshot) Values() []any {
+	s.state.mu.RLock()
+	defer s.state.mu.RUnlock()
+
+	values := make([]any, 0, s.state.length)
+	for current := s.state.head; current != nil; current = current.next {
+		values = append(values, current.Value)
+	}
+	return values
+}
+
+// Range iterates over the snapshot in order and calls f for each key-value
+// pair. If f returns false, iteration stops. f may freely use the live map.
+func (s *Snapshot) Range(f func(key, value any) bool) {
+	s.state.mu.RLock()
+	type entry struct{ key, value any }
+	entries := make([]entry, 0, s.state.length)
+	for current := s.state.head; current != nil; current = current.next {
+		entries = append(entries, entry{current.Key, current.Value})
+	}
+	s.state.mu.RUnlock()
+
+	for _, e := range entries {
+		if !f(e.key, e.value) {
+			break
+		}
+	}
+}
+
+// First returns the first key-value pair of the snapshot.
+func (s *Snapshot) First() (key, value any, exists bool) {
+	s.state.mu.RLock()
+	defer s.state.mu.RUnlock()
+
+	if s.state.head == nil {
+		return nil, nil, false
+	}
+	return s.state.head.Key, s.state.head.Value, true
+}
+
+// Last returns the last key-value pair of the snapshot.
+func (s *Snapshot) Last() (key, value any, exists bool) {
+	s.state.mu.RLock()
+	defer s.state.mu.RUnlock()
+
+	if s.state.tail == nil {
+		return nil, nil, false
+	}
+	return s.state.tail.Key, s.state.tail.Value, true
+}
+
+// String returns a string representation of the snapshot in the same format
+// as OrderedMap.String.
+func (s *Snapshot) String() string {
+	s.state.mu.RLock()
+	defer s.state.mu.RUnlock()
+	return formatNodes(s.state.head)
+}
+
+// MarshalJSON implements the json.Marshaler interface with the same output
+// as OrderedMap.MarshalJSON.
+func (s *Snapshot) MarshalJSON() ([]byte, error) {
+	s.state.mu.RLock()
+	defer s.state.mu.RUnlock()
+	return marshalNodes(s.state.head)
+}
+
+// Copy returns a new, independent OrderedMap with the snapshot's contents
+// and the key normalizer and length limit of the map it was taken from.
+func (s *Snapshot) Copy() *OrderedMap {
+	s.state.mu.RLock()
+	defer s.state.mu.RUnlock()
+
+	newMap := &OrderedMap{
+		nodeMap:   make(map[any]*Node, s.state.length),
+		normalize: s.state.normalize,
+		maxLen:    s.state.maxLen,
+	}
+	for current := s.state.head; current != nil; current = current.next {
+		// Keys are already normalized.
+		_ = newMap.setNormalized(current.Key, current.Value)
+	}
+	return newMap
+}
@@ -0,0 +1,190 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestOrderedMap_Snapshot(t *testing.T) {
+	om := NewOrderedMap()
+	om.Set("a", 1)
+	om.Set("b", 2)
+	om.Set("c", 3)
+
+	snap := om.Snapshot()
+
+	t.Run("Reads", func(t *testing.T) {
+		if snap.Len() != 3 {
+			t.Errorf("Expected 3 elements, got %d", snap.Len())
+		}
+		if v, ok := snap.Get("b"); !ok || v != 2 {
+			t.Errorf("Expected b=2, got %v", v)
+		}
+		if _, ok := snap.Get(nil); ok {
+			t.Error("Expected nil key lookup to fail")
+		}
+		if !reflect.DeepEqual(snap.Keys(), []any{"a", "b", "c"}) {
+			t.Errorf("Unexpected keys %v", snap.Keys())
+		}
+		if !reflect.DeepEqual(snap.Values(), []any{1, 2, 3}) {
+			t.Errorf("Unexpected values %v", snap.Values())
+		}
+		if k, v, ok := snap.First(); !ok || k != "a" || v != 1 {
+			t.Errorf("Unexpected first (%v, %v)", k, v)
+		}
+		if k, v, ok := snap.Last(); !ok || k != "c" || v != 3 {
+			t.Errorf("Unexpected last (%v, %v)", k, v)
+		}
+	})
+
+	t.Run("Isolation From Writes", func(t *testing.T) {
+		om.Set("a", 100)
+		om.Delete("b")
+		om.Set("d", 4)
+		om.MoveToFront("c")
+
+		if snap.String() != "{a: 1, b: 2, c: 3}" {
+			t.Errorf("Snapshot changed after writes: %s", snap.String())
+		}
+		if snap.Has("d") || !snap.Has("b") {
+			t.Error("Snapshot membership changed after writes")
+		}
+		if om.String() != "{c: 3, a: 100, d: 4}" {
+			t.Errorf("Unexpected live map %s", om.String())
+		}
+	})
+
+	t.Run("Isolation From Clear", func(t *testing.T) {
+		before := om.Snapshot()
+		om.Clear()
+		om.Set("x", 1)
+		if before.String() != "{c: 3, a: 100, d: 4}" {
+			t.Errorf("Snapshot changed after Clear: %s", before.String())
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		data, err := json.Marshal(snap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != `{"a":1,"b":2,"c":3}` {
+			t.Errorf("Unexpected JSON %s", data)
+		}
+	})
+
+	t.Run("Range And Copy", func(t *testing.T) {
+		var keys []any
+		snap.Range(func(key, value any) bool {
+			// Writing to the live map from the callback must not deadlock.
+			om.Set(key, value)
+			keys = append(keys, key)
+			return key != "b"
+		})
+		if !reflect.DeepEqual(keys, []any{"a", "b"}) {
+			t.Errorf("Expected iteration to stop after b, got %v", keys)
+		}
+
+		copied := snap.Copy()
+		copied.Set("z", 26)
+		if snap.Has("z") || copied.String() != "{a: 1, b: 2, c: 3, z: 26}" {
+			t.Errorf("Copy is not independent: %s", copied.String())
+		}
+	})
+
+	t.Run("Copy Keeps Options", func(t *testing.T) {
+		om := New(WithKeyNormalizer(lowerKey), WithMaxLen(2))
+		om.Set("A", 1)
+		om.Set("B", 2)
+		copied := om.Snapshot().Copy()
+		if v, ok := copied.Get("a"); !ok || v != 1 {
+			t.Errorf("Expected a case-insensitive lookup to find 1, got %v", v)
+		}
+		copied.Set("C", 3)
+		if copied.String() != "{b: 2, c: 3}" {
+			t.Errorf("Expected {b: 2, c: 3}, got %s", copied.String())
+		}
+	})
+}
+
+func TestOrderedMap_SnapshotSharing(t *testing.T) {
+	om := NewOrderedMap()
+	for i := 0; i < 10; i++ {
+		om.Set(i, i)
+	}
+
+	s1 := om.Snapshot()
+	s2 := om.Snapshot()
+	if s1.state != s2.state {
+		t.Error("Expected snapshots without writes in between to share state")
+	}
+	if s1.state.head != om.head {
+		t.Error("Expected snapshot to share nodes before the first write")
+	}
+
+	om.Set(0, "changed")
+	if om.frozen != nil {
+		t.Error("Expected the write to detach the snapshot")
+	}
+	if s1.state.head == om.head {
+		t.Error("Expected snapshot to own its nodes after the first write")
+	}
+
+	s3 := om.Snapshot()
+	if s3.state == s1.state {
+		t.Error("Expected a new snapshot after a write")
+	}
+	if v, _ := s1.Get(0); v != 0 {
+		t.Errorf("Expected old value 0, got %v", v)
+	}
+	if v, _ := s3.Get(0); v != "changed" {
+		t.Errorf("Expected new value, got %v", v)
+	}
+
+	empty := NewOrderedMap().Snapshot()
+	if _, _, ok := empty.First(); ok {
+		t.Error("Expected First on empty snapshot to fail")
+	}
+	if _, _, ok := empty.Last(); ok {
+		t.Error("Expected Last on empty snapshot to fail")
+	}
+}
+
+func TestOrderedMap_SnapshotConcurrent(t *testing.T) {
+	om := NewOrderedMap()
+	for i := 0; i < 100; i++ {
+		om.Set(i, i)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func(base int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				om.Set(base*1000+i, i)
+				om.Delete(i % 100)
+				om.Set(i%100, -i)
+			}
+		}(g)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				snap := om.Snapshot()
+				n := snap.Len()
+				if len(snap.Keys()) != n || len(snap.Values()) != n {
+					t.Errorf("Inconsistent snapshot: len %d", n)
+					return
+				}
+				snap.Get(i % 100)
+				if _, err := snap.MarshalJSON(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,123 @@
+package orderedmap
+
+import (
+	"context"
+	"fmt"
+)
+
+// WaitFor blocks until the given key is present in the map and returns its
+// value. If the key already exists, it returns immediately. If ctx is done
+// first, it returns ctx.Err().
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+//	defer cancel()
+//	value, err := om.WaitFor(ctx, "result")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (om *OrderedMap) WaitFor(ctx context.Context, key any) (any, error) {
+	if key == nil {
+		return nil, fmt.Errorf("key cannot be nil")
+	}
+
+	for {
+		om.rlock()
+		node, exists := om.lookup(key)
+		var value any
+		if exists {
+			value = node.Value
+		}
+		changed := om.changed()
+		om.runlock()
+
+		if exists {
+			return value, nil
+		}
+		if err := om.waitChange(ctx, changed); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// WaitLen blocks until the map holds at least n elements and returns the
+// length observed at that moment. If ctx is done first, it returns ctx.Err().
+//
+// Example:
+//
+//	if _, err := om.WaitLen(ctx, 10); err != nil {
+//	    log.Fatal(err)
+//	}
+func (om *OrderedMap) WaitLen(ctx context.Context, n int) (int, error) {
+	for {
+		om.rlock()
+		length := om.length
+		changed := om.changed()
+		om.runlock()
+
+		if length >= n {
+			return length, nil
+		}
+		if err := om.waitChange(ctx, changed); err != nil {
+			return length, err
+		}
+	}
+}
+
+// WaitUntil blocks until predicate returns true. The predicate is evaluated
+// once immediately and again after every change to the map. It is called
+// without holding the map's lock, so it may use any method of the map.
+// If ctx is done first, WaitUntil returns ctx.Err().
+//
+// Example:
+//
+//	err := om.WaitUntil(ctx, func(m *OrderedMap) bool {
+//	    return m.Has("a") && m.Has("b")
+//	})
+func (om *OrderedMap) WaitUntil(ctx context.Context, predicate func(om *OrderedMap) bool) error {
+	for {
+		// Register for the next change before looking at the map, so a change
+		// made while the predicate runs is not missed.
+		om.rlock()
+		changed := om.changed()
+		om.runlock()
+
+		if predicate(om) {
+			return nil
+		}
+		if err := om.waitChange(ctx, changed); err != nil {
+			return err
+		}
+	}
+}
+
+// changed returns a channel that is closed by the next change to the map.
+// The caller must hold om.mu, for reading or writing.
+func (om *OrderedMap) changed() <-chan struct{} {
+	om.waitMu.Lock()
+	defer om.waitMu.Unlock()
+	if om.waitc == nil {
+		om.waitc = make(chan struct{})
+	}
+	return om.waitc
+}
+
+// wake releases everyone waiting for a change. The caller must hold om.mu for
+// writing, which excludes every reader that could touch waitc, so waitMu is
+// not needed here.
+func (om *OrderedMap) wake() {
+	if om.waitc != nil {
+		close(om.waitc)
+		om.waitc = nil
+	}
+}
+
+func (om *OrderedMap) waitChange(ctx context.Context, changed <-chan struct{}) error {
+	select {
+	case <-changed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
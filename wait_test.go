@@ -0,0 +1,136 @@
+package orderedmap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOrderedMap_WaitFor(t *testing.T) {
+	t.Run("Already Present", func(t *testing.T) {
+		om := NewOrderedMap()
+		om.Set("ready", 1)
+		v, err := om.WaitFor(context.Background(), "ready")
+		if err != nil || v != 1 {
+			t.Errorf("Expected 1, nil; got %v, %v", v, err)
+		}
+	})
+
+	t.Run("Published Later", func(t *testing.T) {
+		om := NewOrderedMap()
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			om.Set("other", 0)
+			om.Set("result", "done")
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		v, err := om.WaitFor(ctx, "result")
+		if err != nil || v != "done" {
+			t.Errorf("Expected done, nil; got %v, %v", v, err)
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		om := NewOrderedMap()
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := om.WaitFor(ctx, "never")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("Nil Key", func(t *testing.T) {
+		if _, err := NewOrderedMap().WaitFor(context.Background(), nil); err == nil {
+			t.Error("Expected error for nil key")
+		}
+	})
+}
+
+func TestOrderedMap_WaitLen(t *testing.T) {
+	om := NewOrderedMap()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	go func() {
+		for i := 0; i < 5; i++ {
+			om.Set(i, i)
+		}
+	}()
+	n, err := om.WaitLen(ctx, 5)
+	if err != nil || n < 5 {
+		t.Errorf("Expected at least 5, nil; got %d, %v", n, err)
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	if _, err := om.WaitLen(short, 100); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestOrderedMap_WaitUntil(t *testing.T) {
+	om := NewOrderedMap()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	go func() {
+		om.Set("a", 1)
+		time.Sleep(5 * time.Millisecond)
+		om.Set("b", 2)
+	}()
+	err := om.WaitUntil(ctx, func(m *OrderedMap) bool {
+		return m.Has("a") && m.Has("b")
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	err = om.WaitUntil(canceled, func(*OrderedMap) bool { return false })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected Canceled, got %v", err)
+	}
+}
+
+func TestOrderedMap_WaitConcurrent(t *testing.T) {
+	om := NewOrderedMap()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(key int) {
+			defer wg.Done()
+			v, err := om.WaitFor(ctx, key)
+			if err != nil || v != key*2 {
+				t.Errorf("WaitFor(%d) = %v, %v", key, v, err)
+			}
+		}(i)
+	}
+
+	// Waiters that give up early must not disturb the others.
+	shortCtx, shortCancel := context.WithCancel(ctx)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := om.WaitFor(shortCtx, "never"); !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected Canceled, got %v", err)
+			}
+		}()
+	}
+	shortCancel()
+
+	for i := n - 1; i >= 0; i-- {
+		om.Set(i, i*2)
+		om.Delete(-1)
+	}
+	wg.Wait()
+}